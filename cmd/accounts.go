@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/deployer"
+)
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Manage the accounts in a network's keystore",
+	Long: `Creates, imports, lists and exports the accounts in the keystore directory of
+a network in wb.yaml.
+
+Passphrases are read from $` + deployer.PasswordEnv + `, the file named by
+$` + deployer.PasswordFileEnv + ` or the network's password_file, in that order,
+and prompted for otherwise.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("default_network", cmd.Flags().Lookup("network"))
+	},
+}
+
+var accountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the accounts, marking the deployer",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunInRoot(func() error {
+			return listAccounts(viper.GetString("default_network"))
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+var accountsNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create an account",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunInRoot(func() error {
+			return newAccount(viper.GetString("default_network"))
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+var accountsImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import an account from a key file or a hex private key",
+	Long: `Imports an account from a JSON key file, which keeps its passphrase, or from a
+file holding a hex private key, which is encrypted with a new passphrase.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			Fatal(err)
+		}
+
+		err = RunInRoot(func() error {
+			return importAccount(viper.GetString("default_network"), path)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+var accountsExportCmd = &cobra.Command{
+	Use:   "export [ACCOUNT]",
+	Short: "Export an account as a JSON key file",
+	Long: `Exports an account, given by address or keystore index, or else the deployer,
+as a JSON key file encrypted with its passphrase.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		account := ""
+		if len(args) > 0 {
+			account = args[0]
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" {
+			var err error
+			if output, err = filepath.Abs(output); err != nil {
+				Fatal(err)
+			}
+		}
+
+		err := RunInRoot(func() error {
+			return exportAccount(viper.GetString("default_network"), account, output)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(accountsCmd)
+	accountsCmd.AddCommand(accountsListCmd)
+	accountsCmd.AddCommand(accountsNewCmd)
+	accountsCmd.AddCommand(accountsImportCmd)
+	accountsCmd.AddCommand(accountsExportCmd)
+
+	accountsCmd.PersistentFlags().StringP("network", "n", "dev", "network whose keystore to use")
+	accountsExportCmd.Flags().StringP("output", "o", "", "file to write the key to instead of stdout")
+}
+
+func accountsKeystore(network string) (*networkConfig, *keystore.KeyStore, error) {
+	config, err := loadNetworkConfig(network)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ks, err := config.keystore()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, ks, nil
+}
+
+func listAccounts(network string) error {
+	config, ks, err := accountsKeystore(network)
+	if err != nil {
+		return err
+	}
+
+	if len(ks.Accounts()) == 0 {
+		fmt.Printf("No accounts in %s, create one with `wb accounts new`\n", config.Keystore)
+		return nil
+	}
+
+	// A deployer that's no longer in the keystore marks nothing
+	deployerAccount, _ := deployer.Select(ks.Accounts(), config.Deployer)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for i, account := range ks.Accounts() {
+		mark := ""
+		if account.Address == deployerAccount.Address {
+			mark = "deployer"
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\n", i, account.Address.Hex(), mark)
+	}
+
+	return w.Flush()
+}
+
+// newPassphrase returns the passphrase given through the environment or the
+// network's password file, or else prompts for it twice
+func newPassphrase(config *networkConfig) (string, error) {
+	passphrase, ok, err := deployer.Password()
+	if err != nil || ok {
+		return passphrase, err
+	}
+
+	if config.PasswordFile != "" {
+		return deployer.ReadPasswordFile(config.PasswordFile)
+	}
+
+	passphrase, err = prompt.Stdin.PromptPassword("Passphrase for the new account: ")
+	if err != nil {
+		return "", err
+	}
+
+	confirm, err := prompt.Stdin.PromptPassword("Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase != confirm {
+		return "", errors.New("Passphrases don't match")
+	}
+
+	return passphrase, nil
+}
+
+func newAccount(network string) error {
+	config, ks, err := accountsKeystore(network)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := newPassphrase(config)
+	if err != nil {
+		return err
+	}
+
+	account, err := ks.NewAccount(passphrase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Created account", account.Address.Hex(), "in", config.Keystore)
+	return nil
+}
+
+func importAccount(network, path string) error {
+	config, ks, err := accountsKeystore(network)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if json.Valid(data) {
+		passphrase, err := config.passphrase("Passphrase of the key file: ")
+		if err != nil {
+			return err
+		}
+
+		account, err := ks.Import(data, passphrase, passphrase)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Imported account", account.Address.Hex(), "into", config.Keystore)
+		return nil
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return fmt.Errorf("%s is neither a JSON key file nor a hex private key", path)
+	}
+
+	passphrase, err := newPassphrase(config)
+	if err != nil {
+		return err
+	}
+
+	account, err := ks.ImportECDSA(key, passphrase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Imported account", account.Address.Hex(), "into", config.Keystore)
+	return nil
+}
+
+func exportAccount(network, name, output string) error {
+	config, ks, err := accountsKeystore(network)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = config.Deployer
+	}
+
+	account, err := deployer.Select(ks.Accounts(), name)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := config.passphrase(fmt.Sprintf("Passphrase for %s: ", account.Address.Hex()))
+	if err != nil {
+		return err
+	}
+
+	data, err := ks.Export(account, passphrase, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(output, data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Println("Exported account", account.Address.Hex(), "to", output)
+	return nil
+}
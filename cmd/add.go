@@ -7,6 +7,7 @@ import (
 	"regexp"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/zscole/cli/project"
 	"github.com/zscole/cli/templates"
@@ -20,6 +21,8 @@ var addCmd = &cobra.Command{
 var addContractCmd = &cobra.Command{
 	Use:   "contract",
 	Short: "Add a new contract to the project",
+	Long: `Adds a new contract to the project, in Solidity unless another language is
+given with --lang. Vyper contracts are named after their file.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			Fatal("Must specify contract name")
@@ -32,12 +35,18 @@ var addContractCmd = &cobra.Command{
 			Fatal("Invalid contract name specified")
 		}
 
+		language, _ := cmd.Flags().GetString("lang")
+		backend, err := backendForLanguage(language)
+		if err != nil {
+			Fatal(err)
+		}
+
 		project, err := project.FindProject()
 		if err != nil {
 			Fatal(err)
 		}
 
-		addContract(name, project)
+		addContract(name, backend, project)
 	},
 }
 
@@ -94,10 +103,12 @@ func init() {
 	addCmd.AddCommand(addMigrationCmd)
 	addCmd.AddCommand(addTestCmd)
 	RootCmd.AddCommand(addCmd)
+
+	addContractCmd.Flags().String("lang", "solidity", "language of the contract: solidity or vyper")
 }
 
-func addContract(name string, prj *project.Project) {
-	path := filepath.Join(prj.AbsPath(), project.ContractsDirectory, name+".sol")
+func addContract(name string, backend compilerBackend, prj *project.Project) {
+	path := filepath.Join(prj.AbsPath(), project.ContractsDirectory, name+backend.Extension())
 
 	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
 		Fatal(err)
@@ -106,7 +117,7 @@ func addContract(name string, prj *project.Project) {
 	data := prj.TemplateData()
 	data["contract"] = name
 
-	if err := templates.RestoreTemplate(path, "contract/contract.sol.tpl", data); err != nil {
+	if err := templates.RestoreTemplate(path, backend.Template(), data); err != nil {
 		Fatal(err)
 	}
 
@@ -128,8 +139,16 @@ func addMigration(name string, prj *project.Project) {
 		Fatal(err)
 	}
 
+	bindings, err := loadBindingsConfig()
+	if err != nil {
+		Fatal(err)
+	}
+
 	data := prj.TemplateData()
+	data["bindings"] = bindings.importPath(prj)
+	data["bindings_package"] = bindings.Package
 	data["contract"] = name
+	data["type"] = bindings.typeName(name)
 	data["number"] = numMigrations
 
 	if err := templates.RestoreTemplate(path, "migration/migration.go.tpl", data); err != nil {
@@ -146,10 +165,23 @@ func addTest(name string, prj *project.Project) {
 		Fatal(err)
 	}
 
+	bindings, err := loadBindingsConfig()
+	if err != nil {
+		Fatal(err)
+	}
+
 	data := prj.TemplateData()
+	data["bindings"] = bindings.importPath(prj)
+	data["bindings_package"] = bindings.Package
 	data["test"] = name
 
-	if err := templates.RestoreTemplate(path, "test/test.go.tpl", data); err != nil {
+	// Tests on a simulated network start the chain themselves
+	template := "test/test.go.tpl"
+	if config, err := loadNetworkConfig(viper.GetString("test_network")); err == nil && config.simulated() {
+		template = "test/simulated.go.tpl"
+	}
+
+	if err := templates.RestoreTemplate(path, template, data); err != nil {
 		Fatal(err)
 	}
 
@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/project"
+)
+
+const (
+	splitArtifacts    = "split"
+	combinedArtifacts = "combined"
+	bothArtifacts     = "both"
+
+	// hardhatArtifactFormat and truffleSchemaVersion identify the schemas a
+	// combined artifact follows
+	hardhatArtifactFormat = "hh-sol-artifact-1"
+	truffleSchemaVersion  = "3.4.3"
+)
+
+var artifactFormats = []string{splitArtifacts, combinedArtifacts, bothArtifacts}
+
+// artifactsConfig is the `artifacts:` section of wb.yaml
+type artifactsConfig struct {
+	// Format is split for .abi, .bin, .bin-runtime, .link, .srcmap, .layout,
+	// .docs and .ast files per contract, combined
+	// for a single json file compatible with Hardhat and Truffle, or both
+	Format string `mapstructure:"format"`
+}
+
+func loadArtifactsConfig() (*artifactsConfig, error) {
+	config := &artifactsConfig{}
+	if err := viper.UnmarshalKey("artifacts", config); err != nil {
+		return nil, err
+	}
+
+	if config.Format == "" {
+		config.Format = splitArtifacts
+	}
+
+	if !contains(artifactFormats, config.Format) {
+		return nil, fmt.Errorf("artifacts.format: must be one of %s", strings.Join(artifactFormats, ", "))
+	}
+
+	return config, nil
+}
+
+func (c *artifactsConfig) split() bool {
+	return c.Format == splitArtifacts || c.Format == bothArtifacts
+}
+
+func (c *artifactsConfig) combined() bool {
+	return c.Format == combinedArtifacts || c.Format == bothArtifacts
+}
+
+// artifact is everything saved from compiling a contract. Bytecode is hex
+// without a 0x prefix, and may contain library placeholders
+type artifact struct {
+	ContractName           string
+	SourceName             string
+	SourcePath             string
+	Source                 string
+	ABI                    json.RawMessage
+	Bytecode               string
+	DeployedBytecode       string
+	LinkReferences         linkReferences
+	DeployedLinkReferences linkReferences
+	SourceMap              string
+	DeployedSourceMap      string
+	SourceList             []string
+	AST                    json.RawMessage
+	Metadata               string
+	StorageLayout          json.RawMessage
+	Userdoc                json.RawMessage
+	Devdoc                 json.RawMessage
+	Compiler               string
+	CompilerName           string
+
+	// files are the artifact files written for the contract
+	files []string
+}
+
+// combinedArtifact is the union of Hardhat's artifact schema and Truffle's, so
+// the file can be read by tools that expect either
+type combinedArtifact struct {
+	Format                 string                 `json:"_format"`
+	ContractName           string                 `json:"contractName"`
+	SourceName             string                 `json:"sourceName"`
+	ABI                    json.RawMessage        `json:"abi"`
+	Metadata               string                 `json:"metadata,omitempty"`
+	Bytecode               string                 `json:"bytecode"`
+	DeployedBytecode       string                 `json:"deployedBytecode"`
+	LinkReferences         linkReferences         `json:"linkReferences"`
+	DeployedLinkReferences linkReferences         `json:"deployedLinkReferences"`
+	SourceMap              string                 `json:"sourceMap"`
+	DeployedSourceMap      string                 `json:"deployedSourceMap"`
+	SourceList             []string               `json:"sourceList,omitempty"`
+	Source                 string                 `json:"source"`
+	SourcePath             string                 `json:"sourcePath"`
+	AST                    json.RawMessage        `json:"ast,omitempty"`
+	StorageLayout          json.RawMessage        `json:"storageLayout,omitempty"`
+	Userdoc                json.RawMessage        `json:"userdoc,omitempty"`
+	Devdoc                 json.RawMessage        `json:"devdoc,omitempty"`
+	Compiler               combinedCompiler       `json:"compiler"`
+	Networks               map[string]interface{} `json:"networks"`
+	SchemaVersion          string                 `json:"schemaVersion"`
+	UpdatedAt              string                 `json:"updatedAt"`
+}
+
+// sourceMaps is the .srcmap file of a split artifact. Source maps refer to
+// files by their index in SourceList
+type sourceMaps struct {
+	SourceMap         string   `json:"sourceMap"`
+	DeployedSourceMap string   `json:"deployedSourceMap"`
+	SourceList        []string `json:"sourceList"`
+}
+
+// linkFile is the .link file of a split artifact
+type linkFile struct {
+	LinkReferences         linkReferences `json:"linkReferences"`
+	DeployedLinkReferences linkReferences `json:"deployedLinkReferences"`
+}
+
+// natspecDocs is the .docs file of a split artifact
+type natspecDocs struct {
+	Userdoc json.RawMessage `json:"userdoc,omitempty"`
+	Devdoc  json.RawMessage `json:"devdoc,omitempty"`
+}
+
+type combinedCompiler struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// splitArtifactExtensions are every file a split artifact may have
+var splitArtifactExtensions = []string{".abi", ".bin", ".bin-runtime", ".link", ".srcmap", ".layout", ".docs", ".ast"}
+
+// combinedArtifactExtension is the file of a combined artifact
+const combinedArtifactExtension = ".json"
+
+// writeArtifact saves an artifact in the configured formats
+func writeArtifact(a *artifact, config *artifactsConfig) error {
+	path := filepath.Join(project.BuildDirectory, a.ContractName)
+
+	// Bytecode linked from the previous build is stale, `wb link` and
+	// `wb migrate` link the new one
+	removeFiles(linkedFiles(a.ContractName)...)
+
+	if config.split() {
+		links, err := json.Marshal(linkFile{
+			LinkReferences:         a.LinkReferences,
+			DeployedLinkReferences: a.DeployedLinkReferences,
+		})
+		if err != nil {
+			return err
+		}
+
+		maps, err := json.Marshal(sourceMaps{
+			SourceMap:         a.SourceMap,
+			DeployedSourceMap: a.DeployedSourceMap,
+			SourceList:        a.SourceList,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path+".abi", a.ABI, 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path+".bin", []byte(a.Bytecode), 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path+".bin-runtime", []byte(a.DeployedBytecode), 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path+".link", links, 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path+".srcmap", maps, 0644); err != nil {
+			return err
+		}
+		a.files = append(a.files, path+".abi", path+".bin", path+".bin-runtime", path+".link", path+".srcmap")
+
+		if len(a.Userdoc) > 0 || len(a.Devdoc) > 0 {
+			docs, err := json.Marshal(natspecDocs{Userdoc: a.Userdoc, Devdoc: a.Devdoc})
+			if err != nil {
+				return err
+			}
+
+			if err := ioutil.WriteFile(path+".docs", docs, 0644); err != nil {
+				return err
+			}
+			a.files = append(a.files, path+".docs")
+		} else {
+			removeFiles(path + ".docs")
+		}
+
+		if len(a.StorageLayout) > 0 {
+			if err := ioutil.WriteFile(path+".layout", a.StorageLayout, 0644); err != nil {
+				return err
+			}
+			a.files = append(a.files, path+".layout")
+		} else {
+			removeFiles(path + ".layout")
+		}
+
+		if len(a.AST) > 0 {
+			if err := ioutil.WriteFile(path+".ast", a.AST, 0644); err != nil {
+				return err
+			}
+			a.files = append(a.files, path+".ast")
+		} else {
+			removeFiles(path + ".ast")
+		}
+	} else {
+		removeFiles(artifactFiles(path, splitArtifactExtensions...)...)
+	}
+
+	if config.combined() {
+		combined := combinedArtifact{
+			Format:                 hardhatArtifactFormat,
+			ContractName:           a.ContractName,
+			SourceName:             a.SourceName,
+			ABI:                    a.ABI,
+			Metadata:               a.Metadata,
+			Bytecode:               "0x" + a.Bytecode,
+			DeployedBytecode:       "0x" + a.DeployedBytecode,
+			LinkReferences:         a.LinkReferences,
+			DeployedLinkReferences: a.DeployedLinkReferences,
+			SourceMap:              a.SourceMap,
+			DeployedSourceMap:      a.DeployedSourceMap,
+			SourceList:             a.SourceList,
+			Source:                 a.Source,
+			SourcePath:             a.SourcePath,
+			AST:                    a.AST,
+			StorageLayout:          a.StorageLayout,
+			Userdoc:                a.Userdoc,
+			Devdoc:                 a.Devdoc,
+			Compiler:               combinedCompiler{Name: a.CompilerName, Version: a.Compiler},
+			Networks:               make(map[string]interface{}),
+			SchemaVersion:          truffleSchemaVersion,
+			UpdatedAt:              time.Now().UTC().Format(time.RFC3339),
+		}
+
+		// Both schemas expect empty objects rather than nulls
+		if combined.LinkReferences == nil {
+			combined.LinkReferences = make(linkReferences)
+		}
+		if combined.DeployedLinkReferences == nil {
+			combined.DeployedLinkReferences = make(linkReferences)
+		}
+
+		data, err := json.MarshalIndent(combined, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path+combinedArtifactExtension, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+		a.files = append(a.files, path+combinedArtifactExtension)
+	} else {
+		removeFiles(path + combinedArtifactExtension)
+	}
+
+	return nil
+}
+
+// loadArtifact reads the abi, bytecode, link references, source maps, storage
+// layout, NatSpec and AST of a contract from its split artifact files if it has them,
+// or its combined artifact otherwise
+func loadArtifact(name string) (*artifact, error) {
+	return loadArtifactFrom(project.BuildDirectory, name)
+}
+
+// loadArtifactFrom reads a contract's artifact from another build directory
+func loadArtifactFrom(dir, name string) (*artifact, error) {
+	path := filepath.Join(dir, name)
+
+	if _, err := os.Stat(path + ".abi"); err == nil {
+		abi, err := ioutil.ReadFile(path + ".abi")
+		if err != nil {
+			return nil, err
+		}
+
+		bin, err := ioutil.ReadFile(path + ".bin")
+		if err != nil {
+			return nil, err
+		}
+
+		links, err := readLinkFile(path + ".link")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		layout, err := ioutil.ReadFile(path + ".layout")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		// Builds from before these were saved don't have them
+		deployed, err := ioutil.ReadFile(path + ".bin-runtime")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		var maps sourceMaps
+		if data, err := ioutil.ReadFile(path + ".srcmap"); err == nil {
+			if err := json.Unmarshal(data, &maps); err != nil {
+				return nil, fmt.Errorf("%s.srcmap: %v", path, err)
+			}
+		}
+
+		ast, err := ioutil.ReadFile(path + ".ast")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		var docs natspecDocs
+		if data, err := ioutil.ReadFile(path + ".docs"); err == nil {
+			if err := json.Unmarshal(data, &docs); err != nil {
+				return nil, fmt.Errorf("%s.docs: %v", path, err)
+			}
+		}
+
+		return &artifact{
+			ContractName:           name,
+			ABI:                    abi,
+			Bytecode:               strings.TrimSpace(string(bin)),
+			DeployedBytecode:       strings.TrimSpace(string(deployed)),
+			LinkReferences:         links.LinkReferences,
+			DeployedLinkReferences: links.DeployedLinkReferences,
+			SourceMap:              maps.SourceMap,
+			DeployedSourceMap:      maps.DeployedSourceMap,
+			SourceList:             maps.SourceList,
+			AST:                    ast,
+			StorageLayout:          layout,
+			Userdoc:                docs.Userdoc,
+			Devdoc:                 docs.Devdoc,
+		}, nil
+	}
+
+	data, err := ioutil.ReadFile(path + ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	var combined combinedArtifact
+	if err := json.Unmarshal(data, &combined); err != nil {
+		return nil, fmt.Errorf("%s.json: %v", path, err)
+	}
+
+	return &artifact{
+		ContractName:           combined.ContractName,
+		SourceName:             combined.SourceName,
+		SourcePath:             combined.SourcePath,
+		Source:                 combined.Source,
+		ABI:                    combined.ABI,
+		Bytecode:               strings.TrimPrefix(combined.Bytecode, "0x"),
+		DeployedBytecode:       strings.TrimPrefix(combined.DeployedBytecode, "0x"),
+		LinkReferences:         combined.LinkReferences,
+		DeployedLinkReferences: combined.DeployedLinkReferences,
+		SourceMap:              combined.SourceMap,
+		DeployedSourceMap:      combined.DeployedSourceMap,
+		SourceList:             combined.SourceList,
+		AST:                    combined.AST,
+		Metadata:               combined.Metadata,
+		StorageLayout:          combined.StorageLayout,
+		Userdoc:                combined.Userdoc,
+		Devdoc:                 combined.Devdoc,
+		Compiler:               combined.Compiler.Version,
+		CompilerName:           combined.Compiler.Name,
+	}, nil
+}
+
+// artifactNames returns every contract with artifacts in the build directory
+func artifactNames() ([]string, error) {
+	names := make(map[string]bool)
+	for _, ext := range []string{".abi", ".json"} {
+		matches, err := filepath.Glob(filepath.Join(project.BuildDirectory, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			base := filepath.Base(match)
+			if !strings.HasPrefix(base, ".") {
+				names[strings.TrimSuffix(base, ext)] = true
+			}
+		}
+	}
+
+	return sortedKeys(names), nil
+}
+
+// artifactFiles returns the files with the given extensions of the artifact at
+// a path without extension
+func artifactFiles(path string, extensions ...string) []string {
+	files := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		files = append(files, path+ext)
+	}
+
+	return files
+}
+
+// removeFiles removes artifacts left over from a different format
+func removeFiles(paths ...string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
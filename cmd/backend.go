@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// compilerBackend is the compiler of one contract language. Every backend is
+// driven through standard-json and produces output in solc's format, so
+// artifacts, diagnostics and bindings are the same whatever the language
+type compilerBackend interface {
+	// Name is the compiler executable, as installed in the compiler store
+	Name() string
+
+	// Language is the language as named in the standard-json input, and by
+	// `wb add contract --lang`
+	Language() string
+
+	// Extension is the file extension of sources in the language
+	Extension() string
+
+	// Homepage is where the compiler is documented
+	Homepage() string
+
+	// Template is the scaffold `wb add contract` creates
+	Template() string
+
+	// version returns the version string reported by a compiler binary
+	version(path string) (string, error)
+
+	// parseImports returns the imports of a source, with the source unit of
+	// any the language resolves without remappings already set
+	parseImports(name string, content []byte) []sourceImport
+
+	// parsePragmas returns the version constraints of a source
+	parsePragmas(content []byte) []string
+
+	// declaredContracts returns the names of the contracts in a source
+	declaredContracts(name string, content []byte) []string
+
+	// settings returns the standard-json settings for a job
+	settings(job *compileJob) interface{}
+}
+
+var backends = []compilerBackend{solcBackend{}, vyperBackend{}}
+
+// backendForFile returns the backend compiling a source file, or nil if it
+// isn't a contract source
+func backendForFile(file string) compilerBackend {
+	for _, backend := range backends {
+		if path.Ext(file) == backend.Extension() {
+			return backend
+		}
+	}
+
+	return nil
+}
+
+func backendForLanguage(language string) (compilerBackend, error) {
+	names := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		if strings.EqualFold(backend.Language(), language) {
+			return backend, nil
+		}
+		names = append(names, strings.ToLower(backend.Language()))
+	}
+
+	return nil, fmt.Errorf("Unknown language %q, must be one of %s", language, strings.Join(names, ", "))
+}
+
+type solcBackend struct{}
+
+func (solcBackend) Name() string      { return "solc" }
+func (solcBackend) Language() string  { return "Solidity" }
+func (solcBackend) Extension() string { return ".sol" }
+func (solcBackend) Homepage() string  { return "https://docs.soliditylang.org" }
+func (solcBackend) Template() string  { return "contract/contract.sol.tpl" }
+
+// version returns the version string reported by solc, e.g.
+// "0.4.24+commit.e67f0147.Linux.g++"
+func (solcBackend) version(command string) (string, error) {
+	out, err := exec.Command(command, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimPrefix(lines[len(lines)-1], "Version: "), nil
+}
+
+func (solcBackend) parseImports(name string, content []byte) []sourceImport {
+	return parseImports(content)
+}
+
+func (solcBackend) parsePragmas(content []byte) []string {
+	return parsePragmas(content)
+}
+
+func (solcBackend) declaredContracts(name string, content []byte) []string {
+	return declaredContracts(content)
+}
+
+func (solcBackend) settings(job *compileJob) interface{} {
+	return job.Settings
+}
+
+type vyperBackend struct{}
+
+// vyperOutputs is what vyper is asked to produce for every contract
+var vyperOutputs = []string{
+	"abi",
+	"ast",
+	"userdoc",
+	"devdoc",
+	"evm.bytecode.object",
+	"evm.bytecode.sourceMap",
+	"evm.deployedBytecode.object",
+	"evm.deployedBytecode.sourceMap",
+}
+
+var (
+	vyperPragmaPattern = regexp.MustCompile(`(?m)^\s*#\s*(?:@version|pragma\s+version)\s+(.+?)\s*$`)
+	vyperImportPattern = regexp.MustCompile(`(?m)^\s*(?:from\s+([\w.]+)\s+)?import\s+([\w.]+)`)
+)
+
+// vyperSettings is the settings object of the vyper standard-json input
+type vyperSettings struct {
+	EVMVersion      string              `json:"evmVersion,omitempty"`
+	Optimize        interface{}         `json:"optimize"`
+	OutputSelection map[string][]string `json:"outputSelection"`
+}
+
+func (vyperBackend) Name() string      { return "vyper" }
+func (vyperBackend) Language() string  { return "Vyper" }
+func (vyperBackend) Extension() string { return ".vy" }
+func (vyperBackend) Homepage() string  { return "https://docs.vyperlang.org" }
+func (vyperBackend) Template() string  { return "contract/contract.vy.tpl" }
+
+// version returns the version string reported by vyper, e.g.
+// "0.3.10+commit.91361694"
+func (vyperBackend) version(command string) (string, error) {
+	out, err := exec.Command(command, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseImports resolves vyper's module imports to the files they name. Any
+// that don't resolve to a project source, such as the built in interfaces,
+// are left to vyper
+func (vyperBackend) parseImports(name string, content []byte) []sourceImport {
+	code := stripVyperComments(string(content))
+
+	imports := make([]sourceImport, 0)
+	for _, loc := range vyperImportPattern.FindAllStringSubmatchIndex(code, -1) {
+		module := code[loc[4]:loc[5]]
+		if loc[2] >= 0 {
+			module = code[loc[2]:loc[3]] + "." + module
+		}
+
+		unit := strings.Replace(strings.TrimLeft(module, "."), ".", "/", -1) + ".vy"
+		if strings.HasPrefix(module, ".") {
+			unit = path.Join(path.Dir(name), unit)
+		}
+
+		imports = append(imports, sourceImport{
+			Path: module,
+			Line: strings.Count(code[:loc[0]], "\n") + 1,
+			Unit: unit,
+		})
+	}
+
+	return imports
+}
+
+func (vyperBackend) parsePragmas(content []byte) []string {
+	pragmas := make([]string, 0)
+	for _, m := range vyperPragmaPattern.FindAllStringSubmatch(string(content), -1) {
+		pragmas = append(pragmas, m[1])
+	}
+
+	return pragmas
+}
+
+// declaredContracts returns the one contract in a vyper source, named after
+// its file
+func (vyperBackend) declaredContracts(name string, content []byte) []string {
+	return []string{strings.TrimSuffix(path.Base(name), ".vy")}
+}
+
+// settings translates the solc settings of a job to vyper's. Only the
+// optimizer and EVM version have an equivalent
+func (vyperBackend) settings(job *compileJob) interface{} {
+	settings := vyperSettings{
+		EVMVersion:      job.Settings.EVMVersion,
+		OutputSelection: make(map[string][]string),
+	}
+
+	// vyper 0.3.10 replaced the boolean with optimization modes
+	settings.Optimize = job.Settings.Optimizer.Enabled
+	if job.Compiler.Version.compare(version{0, 3, 10}) >= 0 {
+		settings.Optimize = "none"
+		if job.Settings.Optimizer.Enabled {
+			settings.Optimize = "gas"
+		}
+	}
+
+	for _, name := range job.Sources {
+		settings.OutputSelection[name] = vyperOutputs
+	}
+
+	return settings
+}
+
+// stripVyperComments blanks out vyper comments while preserving line breaks
+func stripVyperComments(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "#"); j >= 0 {
+			lines[i] = line[:j]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
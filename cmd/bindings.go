@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/abigen"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/project"
+)
+
+const defaultBindingsPackage = "bindings"
+
+// bindingsConfig is the `bindings:` section of wb.yaml
+type bindingsConfig struct {
+	Package string `mapstructure:"package"`
+	Output  string `mapstructure:"output"`
+
+	// Types renames the Go types generated for contracts, which otherwise
+	// share the contract's name
+	Types map[string]string `mapstructure:"types"`
+}
+
+func loadBindingsConfig() (*bindingsConfig, error) {
+	config := &bindingsConfig{}
+	if err := viper.UnmarshalKey("bindings", config); err != nil {
+		return nil, err
+	}
+
+	if config.Package == "" {
+		config.Package = defaultBindingsPackage
+	}
+	if config.Output == "" {
+		config.Output = project.BindingsDirectory
+	}
+
+	if !token.IsIdentifier(config.Package) {
+		return nil, fmt.Errorf("bindings.package: %q is not a valid Go package name", config.Package)
+	}
+
+	// viper lowercases map keys, so contracts are matched regardless of case
+	types := make(map[string]string)
+	for contract, name := range config.Types {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("bindings.types.%s: %q is not a valid Go identifier", contract, name)
+		}
+		types[strings.ToLower(contract)] = name
+	}
+	config.Types = types
+
+	return config, nil
+}
+
+// typeName is the Go type generated for a contract
+func (c *bindingsConfig) typeName(contract string) string {
+	if name, ok := c.Types[strings.ToLower(contract)]; ok {
+		return name
+	}
+
+	return contract
+}
+
+func (c *bindingsConfig) path(contract string) string {
+	return filepath.Join(c.Output, contract+".go")
+}
+
+// importPath is the package path migrations and tests import the bindings from
+func (c *bindingsConfig) importPath(prj *project.Project) string {
+	return strings.TrimSuffix(prj.Name()+"/"+filepath.ToSlash(filepath.Clean(c.Output)), "/.")
+}
+
+// hash identifies a binding by the artifacts and config it was generated from
+func (c *bindingsConfig) hash(contract, artifacts string) string {
+	return hashBytes([]byte(artifacts), []byte(c.Package), []byte(c.typeName(contract)))
+}
+
+func generateBindings() error {
+	config, err := loadBindingsConfig()
+	if err != nil {
+		return err
+	}
+
+	names, err := artifactNames()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(config.Output, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	cache := loadBuildCache()
+	pending := make(map[string]string)
+	order := make([]string, 0)
+	for _, name := range names {
+		artifacts, err := artifactHash(name)
+		if err != nil {
+			return err
+		}
+
+		// Skip bindings whose artifacts haven't changed since they were generated
+		hash := config.hash(name, artifacts)
+		if _, err := os.Stat(config.path(name)); err == nil && cache.Bindings[name] == hash && !viper.GetBool("force") {
+			continue
+		}
+
+		pending[name] = hash
+		order = append(order, name)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []string
+	)
+
+	// Bindings are independent of each other, so they're generated in parallel
+	queue := make(chan string)
+	for i := 0; i < parallelism(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				a, err := loadArtifact(name)
+				if err == nil {
+					err = generateBinding(config, a, a.Bytecode)
+				}
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+				} else {
+					cache.Bindings[name] = pending[name]
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, name := range order {
+		queue <- name
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := cache.save(); err != nil {
+		return err
+	}
+
+	if err := recordBindings(config, names); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("Failed to generate bindings:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// generateBinding writes the Go binding of a contract from its abi and the
+// given bytecode, replacing any previous binding atomically
+func generateBinding(config *bindingsConfig, a *artifact, bin string) error {
+	name := a.ContractName
+	code, err := abigen.Bind(
+		[]string{config.typeName(name)},
+		[]string{string(a.ABI)},
+		[]string{strings.TrimSpace(bin)},
+		nil,
+		config.Package,
+		make(map[string]string),
+		make(map[string]string),
+	)
+	if err != nil {
+		return err
+	}
+
+	docs, err := parseNatspec(a)
+	if err != nil {
+		return err
+	}
+
+	code, err = annotateBinding(code, config.typeName(name), a.ABI, docs)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(config.path(name), []byte(code), 0644)
+}
+
+var (
+	bindingTypeComment   = regexp.MustCompile(`^// (\w+) is an auto generated Go binding around an Ethereum contract\.$`)
+	bindingMemberComment = regexp.MustCompile(`^// \w+ is .* binding the contract (?:method|event) (0x[0-9a-f]+)\.$`)
+)
+
+// annotateBinding adds the NatSpec of a contract and its functions and events
+// to the comments of the generated binding, after the first line of each
+func annotateBinding(code, typeName string, abi []byte, docs *natspec) (string, error) {
+	entries, err := parseABI(abi)
+	if err != nil {
+		return "", err
+	}
+
+	contract := natspecEntry{Notice: docs.Userdoc.Notice, Details: docs.Devdoc.Details}
+	members := make(map[string]natspecEntry)
+	for _, entry := range entries {
+		switch entry.Type {
+		case "function":
+			members[entry.selector()] = docs.method(entry.signature())
+		case "event":
+			members[entry.selector()] = docs.event(entry.signature())
+		}
+	}
+
+	lines := strings.Split(code, "\n")
+	annotated := make([]string, 0, len(lines))
+	for _, line := range lines {
+		annotated = append(annotated, line)
+
+		var doc []string
+		if m := bindingTypeComment.FindStringSubmatch(line); m != nil && m[1] == typeName {
+			doc = contract.lines()
+		} else if m := bindingMemberComment.FindStringSubmatch(line); m != nil {
+			doc = members[m[1]].lines()
+		}
+
+		if len(doc) > 0 {
+			annotated = append(annotated, "//")
+			for _, text := range doc {
+				annotated = append(annotated, strings.TrimRight("// "+text, " "))
+			}
+		}
+	}
+
+	return strings.Join(annotated, "\n"), nil
+}
+
+// writeFileAtomic writes to a temporary file alongside path and renames it into
+// place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+// recordBindings adds the bindings of every contract to the build manifest,
+// removing bindings of contracts that no longer exist and those left behind
+// when the bindings config changed
+func recordBindings(config *bindingsConfig, names []string) error {
+	manifest := loadBuildManifest()
+
+	existing := make(map[string]bool)
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	for name, path := range manifest.Bindings {
+		if !existing[name] || path != config.path(name) {
+			removeFiles(path)
+			delete(manifest.Bindings, name)
+		}
+	}
+
+	for _, name := range names {
+		if _, err := os.Stat(config.path(name)); err == nil {
+			manifest.Bindings[name] = config.path(name)
+		}
+	}
+
+	return manifest.save()
+}
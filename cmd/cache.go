@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zscole/cli/project"
+)
+
+const buildCacheFilename = ".cache.json"
+
+// buildCache records what the last successful compile was built from, so
+// unchanged sources and bindings can be skipped on the next run
+type buildCache struct {
+	Sources map[string]cachedSource `json:"sources"`
+
+	// Artifacts is the format artifacts were saved in, since changing it
+	// means rewriting all of them
+	Artifacts string `json:"artifacts"`
+
+	// Bindings maps each contract to the artifact hash its binding was
+	// generated from
+	Bindings map[string]string `json:"bindings"`
+}
+
+// cachedSource is the state a source unit was last compiled in
+type cachedSource struct {
+	// Hash covers the source and everything it imports
+	Hash     string `json:"hash"`
+	Compiler string `json:"compiler"`
+	Settings string `json:"settings"`
+}
+
+func newBuildCache() *buildCache {
+	return &buildCache{
+		Sources:  make(map[string]cachedSource),
+		Bindings: make(map[string]string),
+	}
+}
+
+func buildCachePath() string {
+	return filepath.Join(project.BuildDirectory, buildCacheFilename)
+}
+
+// loadBuildCache reads the cache from the build directory, returning an empty
+// cache if none exists or it can't be parsed
+func loadBuildCache() *buildCache {
+	cache := newBuildCache()
+
+	data, err := ioutil.ReadFile(buildCachePath())
+	if err == nil {
+		if err := json.Unmarshal(data, cache); err != nil {
+			cache = newBuildCache()
+		}
+	}
+
+	if cache.Sources == nil {
+		cache.Sources = make(map[string]cachedSource)
+	}
+	if cache.Bindings == nil {
+		cache.Bindings = make(map[string]string)
+	}
+
+	return cache
+}
+
+func (c *buildCache) save() error {
+	if err := os.MkdirAll(project.BuildDirectory, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(buildCachePath(), data, 0644)
+}
+
+// dirty returns the sources that need recompiling: new or changed files, and
+// files whose imports changed or that need a different compiler or settings
+func (c *buildCache) dirty(sources sourceSet, compilers map[string]*installedCompiler, plan *settingsPlan) []string {
+	dirty := make([]string, 0)
+	for _, name := range sources.names() {
+		if c.Sources[name] != sourceState(sources, compilers, plan, name) {
+			dirty = append(dirty, name)
+		}
+	}
+
+	return dirty
+}
+
+// update records the state of a successful compile
+func (c *buildCache) update(sources sourceSet, compilers map[string]*installedCompiler, plan *settingsPlan, artifacts *artifactsConfig) {
+	c.Artifacts = artifacts.Format
+	c.Sources = make(map[string]cachedSource)
+	for _, name := range sources.names() {
+		c.Sources[name] = sourceState(sources, compilers, plan, name)
+	}
+}
+
+func sourceState(sources sourceSet, compilers map[string]*installedCompiler, plan *settingsPlan, name string) cachedSource {
+	return cachedSource{
+		Hash:     sources.unitHash(name),
+		Compiler: compilers[name].Version.String(),
+		Settings: plan.hash(name),
+	}
+}
+
+func artifactHash(name string) (string, error) {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return "", err
+	}
+
+	return hashBytes(a.ABI, []byte(a.Bytecode), a.Userdoc, a.Devdoc), nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testSources builds a source set from contents keyed by source unit name,
+// resolving imports as loadSources does without remappings
+func testSources(contents map[string]string) sourceSet {
+	sources := make(sourceSet)
+	for name, content := range contents {
+		source := newSourceFile(name, name, []byte(content))
+		for i, imp := range source.Imports {
+			source.Imports[i].Unit = resolveImport(name, imp.Path)
+		}
+		sources[name] = source
+	}
+
+	return sources
+}
+
+func TestBuildCacheDirty(t *testing.T) {
+	contents := map[string]string{
+		"Token.sol":      `import "./lib/Math.sol"; contract Token {}`,
+		"Vault.sol":      `contract Vault {}`,
+		"lib/Math.sol":   `library Math {}`,
+		"lib/Unused.sol": `library Unused {}`,
+	}
+
+	compiler := func(v version) map[string]*installedCompiler {
+		compilers := make(map[string]*installedCompiler)
+		for name := range contents {
+			compilers[name] = &installedCompiler{Backend: solcBackend{}, Version: v}
+		}
+		return compilers
+	}
+
+	plan := func() *settingsPlan {
+		plan := &settingsPlan{Sources: make(map[string]solcSettings), Contracts: make(map[string]map[string]solcSettings)}
+		for name := range contents {
+			plan.Sources[name] = solcSettings{Optimizer: solcOptimizer{Enabled: true, Runs: 200}}
+		}
+		return plan
+	}
+
+	cache := newBuildCache()
+	cache.update(testSources(contents), compiler(version{0, 8, 19}), plan(), &artifactsConfig{Format: splitArtifacts})
+
+	tests := []struct {
+		name      string
+		edit      func(contents map[string]string)
+		compilers map[string]*installedCompiler
+		plan      func(plan *settingsPlan)
+		want      []string
+	}{
+		{
+			name: "unchanged",
+			want: []string{},
+		},
+		{
+			name: "changed source",
+			edit: func(contents map[string]string) { contents["Vault.sol"] = `contract Vault { uint x; }` },
+			want: []string{"Vault.sol"},
+		},
+		{
+			name: "changed import",
+			edit: func(contents map[string]string) { contents["lib/Math.sol"] = `library Math { }` },
+			want: []string{"Token.sol", "lib/Math.sol"},
+		},
+		{
+			name: "new source",
+			edit: func(contents map[string]string) { contents["Escrow.sol"] = `contract Escrow {}` },
+			want: []string{"Escrow.sol"},
+		},
+		{
+			name:      "different compiler",
+			compilers: map[string]*installedCompiler{"Vault.sol": {Backend: solcBackend{}, Version: version{0, 8, 20}}},
+			want:      []string{"Vault.sol"},
+		},
+		{
+			name: "different settings",
+			plan: func(plan *settingsPlan) { plan.Sources["Token.sol"] = solcSettings{EVMVersion: "paris"} },
+			want: []string{"Token.sol"},
+		},
+		{
+			name: "contract settings",
+			plan: func(plan *settingsPlan) {
+				plan.Contracts["Vault.sol"] = map[string]solcSettings{"Vault": {ViaIR: true}}
+			},
+			want: []string{"Vault.sol"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edited := make(map[string]string)
+			for name, content := range contents {
+				edited[name] = content
+			}
+			if test.edit != nil {
+				test.edit(edited)
+			}
+
+			compilers := compiler(version{0, 8, 19})
+			compilers["Escrow.sol"] = compilers["Vault.sol"]
+			for name, c := range test.compilers {
+				compilers[name] = c
+			}
+
+			p := plan()
+			if test.plan != nil {
+				test.plan(p)
+			}
+
+			got := cache.dirty(testSources(edited), compilers, p)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("dirty() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
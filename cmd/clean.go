@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zscole/cli/project"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove build artifacts and generated bindings",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := RunInRoot(clean); err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(cleanCmd)
+}
+
+// clean removes the build directory, and every binding generated from it.
+// Other files alongside the bindings are left alone
+func clean() error {
+	config, err := loadBindingsConfig()
+	if err != nil {
+		return err
+	}
+
+	manifest := loadBuildManifest()
+	bindings := make(map[string]bool)
+	for _, path := range manifest.Bindings {
+		bindings[path] = true
+	}
+
+	// Bindings built before the manifest was kept are found by their contracts
+	names, err := artifactNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		bindings[config.path(name)] = true
+	}
+
+	removed := 0
+	for _, path := range sortedKeys(bindings) {
+		if err := os.Remove(path); err == nil {
+			removed++
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	// Only remove binding directories that are now empty
+	dirs := make(map[string]bool)
+	for path := range bindings {
+		dirs[filepath.Dir(path)] = true
+	}
+	for dir := range dirs {
+		os.Remove(dir)
+	}
+
+	if err := os.RemoveAll(project.BuildDirectory); err != nil {
+		return err
+	}
+
+	fmt.Println("Removed", project.BuildDirectory, "and", removed, "bindings")
+	return nil
+}
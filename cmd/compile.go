@@ -4,249 +4,523 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/zscole/cli/project"
 	"github.com/zscole/cli/templates"
 )
 
 var compileCmd = &cobra.Command{
-	Use:   "compile",
-	Short: "Compile contract source files",
+	Use:    "compile",
+	Short:  "Compile contract source files",
+	PreRun: bindCompileFlags,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := RunInRoot(func() error {
-			if err := compileContracts(); err != nil {
-				return err
+		// Errors go after the diagnostics, so they're kept out of a json or
+		// sarif document on stdout
+		out := progressOutput(viper.GetString("format"))
+
+		if viper.GetBool("watch") {
+			if err := RunInRoot(func() error { return watch(false) }); err != nil {
+				FatalTo(out, err)
 			}
+			return
+		}
 
-			return generateBindings()
-		})
-		if err != nil {
-			Fatal(err)
+		if err := RunInRoot(compile); err != nil {
+			FatalTo(out, err)
 		}
 	},
 }
 
 var buildCmd = &cobra.Command{
-	Use:   "build",
-	Short: "(alias for compile)",
-	Run:   compileCmd.Run,
+	Use:    "build",
+	Short:  "(alias for compile)",
+	PreRun: bindCompileFlags,
+	Run:    compileCmd.Run,
 }
 
 var generateCmd = &cobra.Command{
-	Use:   "generate",
-	Short: "(alias for compile)",
-	Run:   compileCmd.Run,
+	Use:    "generate",
+	Short:  "(alias for compile)",
+	PreRun: bindCompileFlags,
+	Run:    compileCmd.Run,
 }
 
 func init() {
 	RootCmd.AddCommand(compileCmd)
 	RootCmd.AddCommand(buildCmd)
 	RootCmd.AddCommand(generateCmd)
+
+	for _, cmd := range []*cobra.Command{compileCmd, buildCmd, generateCmd} {
+		cmd.PersistentFlags().Bool("force", false, "ignore the build cache and recompile everything")
+		cmd.PersistentFlags().Bool("show-config", false, "print the effective compiler settings and exit")
+		cmd.PersistentFlags().Bool("watch", false, "recompile whenever contracts change")
+		cmd.PersistentFlags().String("format", "human", "format of compiler diagnostics: human, json or sarif")
+		cmd.PersistentFlags().Int("jobs", 0, "number of compilers and binding generators to run at once (default the number of CPUs)")
+	}
 }
 
-func compileContracts() error {
-	if err := os.Chdir(project.ContractsDirectory); err != nil {
-		return err
+// bindCompileFlags binds the flags of whichever compile alias is being run
+func bindCompileFlags(cmd *cobra.Command, args []string) {
+	viper.BindPFlag("force", cmd.Flags().Lookup("force"))
+	viper.BindPFlag("show-config", cmd.Flags().Lookup("show-config"))
+	viper.BindPFlag("watch", cmd.Flags().Lookup("watch"))
+	viper.BindPFlag("format", cmd.Flags().Lookup("format"))
+	viper.BindPFlag("jobs", cmd.Flags().Lookup("jobs"))
+}
+
+// parallelism is how many compilers or binding generators may run at once
+func parallelism() int {
+	if jobs := viper.GetInt("jobs"); jobs > 0 {
+		return jobs
 	}
 
-	type match struct {
-		Filename string
-		Content  string
+	return runtime.NumCPU()
+}
+
+// compile builds the contracts and generates their bindings
+func compile() error {
+	if err := compileContracts(); err != nil {
+		return err
 	}
 
-	matches := make([]match, 0)
-	err := filepath.Walk(".", func(path string, f os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	return generateBindings()
+}
 
-		if strings.HasSuffix(path, ".sol") {
-			content, err := ioutil.ReadFile(path)
-			if err != nil {
-				return err
-			}
+func compileContracts() error {
+	reporter, err := newDiagnosticReporter(viper.GetString("format"))
+	if err != nil {
+		return err
+	}
 
-			matches = append(matches, match{Filename: path, Content: strconv.Quote(string(content))})
-		}
-		return nil
-	})
+	config, err := loadCompilerConfig()
+	if err != nil {
+		return err
+	}
 
+	sources, err := loadSources(config)
 	if err != nil {
 		return err
 	}
 
-	if len(matches) == 0 {
-		Fatal("No contracts found, create one with `wb add contract NAME`")
+	if len(sources.names()) == 0 {
+		return errors.New("No contracts found, create one with `wb add contract NAME`")
+	}
+
+	plan := planSettings(sources, config)
+	if viper.GetBool("show-config") {
+		return showSettings(plan)
 	}
 
-	command := "solc"
-	_, err = exec.LookPath(command)
+	compilers, err := installedCompilers()
 	if err != nil {
-		return errors.New("Can't locate solc, is it installed and in your path")
+		return err
 	}
 
-	compilerConfig, err := templates.ExecuteTemplate("solc/solc.json.tpl", matches)
+	selected, err := selectCompilers(sources, compilers)
 	if err != nil {
 		return err
 	}
 
-	args := []string{"--standard-json"}
-	outputJson, err := ExecWithPipes(command, compilerConfig.Bytes(), args...)
+	artifacts, err := loadArtifactsConfig()
 	if err != nil {
 		return err
 	}
 
-	if err = os.Chdir(".."); err != nil {
+	// Without a manifest there's no telling which outputs are stale, so
+	// everything is rebuilt and anything else in the build directory pruned
+	cache := loadBuildCache()
+	if viper.GetBool("force") || cache.Artifacts != artifacts.Format || !loadBuildManifest().exists {
+		cache = newBuildCache()
+	}
+
+	dirty := cache.dirty(sources, selected, plan)
+	if len(dirty) == 0 {
+		fmt.Fprintln(reporter.progress(), "Contracts are up to date")
+
+		// Sources may still have been deleted
+		if err := pruneOutputs(reporter.progress(), sources, nil, nil, cache); err != nil {
+			return err
+		}
+		if err := cache.save(); err != nil {
+			return err
+		}
+
+		if err := reporter.flush(); err != nil {
+			return err
+		}
+
+		return checkSelectorCollisions()
+	}
+
+	// Sources needing different compilers or settings, or that don't import
+	// each other, are compiled separately and at the same time. Every job is
+	// run even if one fails, so all diagnostics are reported at once
+	jobs := plan.jobs(sources, dirty, selected)
+	results := runJobs(jobs, sources, config)
+
+	if err := os.MkdirAll(project.BuildDirectory, os.FileMode(0755)); err != nil {
 		return err
 	}
 
-	var output map[string]interface{}
-	if err = json.Unmarshal(outputJson, &output); err != nil {
+	// Results are reported and saved in job order, whichever finished first,
+	// so output is the same from run to run and artifacts of contracts with
+	// their own settings replace those from the rest of their file
+	built := make([]*artifact, 0)
+	for i, job := range jobs {
+		result := <-results[i]
+		if result.err != nil {
+			return result.err
+		}
+
+		fmt.Fprintln(reporter.progress(), "Compiling", job)
+		reporter.report(result.diagnostics)
+
+		for _, a := range result.artifacts {
+			if err := writeArtifact(a, artifacts); err != nil {
+				return err
+			}
+		}
+		built = append(built, result.artifacts...)
+	}
+
+	if err := reporter.flush(); err != nil {
 		return err
 	}
 
-	if err = handleErrors(output); err != nil {
+	if reporter.fatal() > 0 {
+		// Jobs that succeeded saved their artifacts, which are recorded so
+		// they're pruned if their sources go away before the next build
+		if manifest := loadBuildManifest(); manifest.exists {
+			manifest.record(built)
+			if err := manifest.save(); err != nil {
+				return err
+			}
+		}
+
+		return errors.New("Error detected, aborting. Please check compiler output for more details.")
+	}
+
+	if err := checkSizes(reporter.progress(), built); err != nil {
+		return err
+	}
+
+	if err := pruneOutputs(reporter.progress(), sources, dirty, built, cache); err != nil {
 		return err
 	}
 
-	return saveArtifacts(output)
+	cache.update(sources, selected, plan, artifacts)
+	if err := cache.save(); err != nil {
+		return err
+	}
+
+	// Collisions are checked across the whole build, rebuilt or not
+	return checkSelectorCollisions()
 }
 
-func handleErrors(output map[string]interface{}) error {
-	hasFatal := false
-	compilerErrors, ok := output["errors"].([]interface{})
-	if !ok {
-		return errors.New("Invalid json")
+// jobResult is what a compile job produced. Artifacts are only set if
+// compiling succeeded
+type jobResult struct {
+	artifacts   []*artifact
+	diagnostics []diagnostic
+	err         error
+}
+
+// runJobs starts the jobs on a pool of workers, returning a channel for the
+// result of each
+func runJobs(jobs []*compileJob, sources sourceSet, config *compilerConfig) []chan jobResult {
+	results := make([]chan jobResult, len(jobs))
+	for i := range jobs {
+		results[i] = make(chan jobResult, 1)
 	}
 
-	for _, value := range compilerErrors {
-		compilerErr, ok := value.(map[string]interface{})
-		if !ok {
-			return errors.New("Invalid json")
-		}
+	queue := make(chan int)
+	for i := 0; i < parallelism(); i++ {
+		go func() {
+			for i := range queue {
+				results[i] <- jobs[i].run(sources, config)
+			}
+		}()
+	}
 
-		severity, ok := compilerErr["severity"].(string)
-		if !ok {
-			return errors.New("Invalid json")
+	go func() {
+		for i := range jobs {
+			queue <- i
 		}
+		close(queue)
+	}()
 
-		message, ok := compilerErr["formattedMessage"].(string)
-		if !ok {
-			return errors.New("Invalid json")
-		}
+	return results
+}
 
-		if severity != "warning" {
-			hasFatal = true
-		}
+// run compiles the sources of a job, along with everything they import, and
+// returns the artifacts of the contracts the job owns unless compiling failed
+func (job *compileJob) run(sources sourceSet, config *compilerConfig) jobResult {
+	type match struct {
+		Filename string
+		Content  string
+	}
 
-		fmt.Println(message)
+	matches := make([]match, 0)
+	for _, name := range sources.closure(job.Sources) {
+		matches = append(matches, match{Filename: name, Content: strconv.Quote(string(sources[name].Content))})
 	}
 
-	if hasFatal {
-		return errors.New("Error detected, aborting. Please check solidity output for more details.")
+	settings, err := json.MarshalIndent(job.Compiler.Backend.settings(job), "  ", "  ")
+	if err != nil {
+		return jobResult{err: err}
 	}
 
-	return nil
-}
+	data := map[string]interface{}{
+		"language": job.Compiler.Backend.Language(),
+		"sources":  matches,
+		"settings": string(settings),
+	}
 
-func saveArtifacts(output map[string]interface{}) error {
-	if err := os.MkdirAll(project.BuildDirectory, os.FileMode(0755)); err != nil {
-		return err
+	compilerConfig, err := templates.ExecuteTemplate("solc/solc.json.tpl", data)
+	if err != nil {
+		return jobResult{err: err}
 	}
 
+	args := []string{"--standard-json"}
+	outputJson, err := ExecWithPipes(job.Compiler.Path, compilerConfig.Bytes(), args...)
+	if err != nil {
+		return jobResult{err: err}
+	}
+
+	var output map[string]interface{}
+	if err = json.Unmarshal(outputJson, &output); err != nil {
+		return jobResult{err: err}
+	}
+
+	diagnostics, err := parseDiagnostics(output, sources, job.Compiler, config)
+	if err != nil {
+		return jobResult{err: err}
+	}
+
+	for _, d := range diagnostics {
+		if d.Fatal {
+			return jobResult{diagnostics: diagnostics}
+		}
+	}
+
+	artifacts, err := parseArtifacts(output, sources, job)
+	return jobResult{artifacts: artifacts, diagnostics: diagnostics, err: err}
+}
+
+// parseArtifacts returns the artifacts of every contract owned by a job, and
+// of any library they need linked
+func parseArtifacts(output map[string]interface{}, sources sourceSet, job *compileJob) ([]*artifact, error) {
 	contracts, ok := output["contracts"].(map[string]interface{})
 	if !ok {
-		return errors.New("Invalid json")
+		return nil, errors.New("Invalid json")
 	}
 
-	for _, value := range contracts {
+	// The AST is per source rather than per contract
+	units, _ := output["sources"].(map[string]interface{})
+	sourceList := sourceIndex(units)
+
+	saved := make([]*artifact, 0)
+	libraries := linkedLibraries(contracts, job)
+	for source, value := range contracts {
 		contract, ok := value.(map[string]interface{})
 		if !ok {
-			return errors.New("Invalid json")
+			return nil, errors.New("Invalid json")
 		}
 
 		for name, value := range contract {
+			if !job.owns(source, name) && !libraries[source+":"+name] {
+				continue
+			}
+
 			data, ok := value.(map[string]interface{})
 			if !ok {
-				return errors.New("Invalid json")
+				return nil, errors.New("Invalid json")
 			}
 
-			// Get ABI as a json blob
-			abi, err := json.Marshal(data["abi"])
+			a, err := parseArtifact(data)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("%s:%s: %v", source, name, err)
 			}
 
-			evm, ok := data["evm"].(map[string]interface{})
-			if !ok {
-				return errors.New("Invalid json")
+			a.ContractName = name
+			a.SourceName = source
+			a.SourceList = sourceList
+			a.Compiler = job.Compiler.Version.String()
+			a.CompilerName = job.Compiler.Backend.Name()
+			if file, ok := sources[source]; ok {
+				a.SourcePath = filepath.ToSlash(file.Path)
+				a.Source = string(file.Content)
 			}
 
-			bytecodeObject, ok := evm["bytecode"].(map[string]interface{})
-			if !ok {
-				return errors.New("Invalid json")
+			if unit, ok := units[source].(map[string]interface{}); ok && unit["ast"] != nil {
+				if a.AST, err = json.Marshal(unit["ast"]); err != nil {
+					return nil, err
+				}
 			}
 
-			// Get bytecode as a bin
-			bytecode, ok := bytecodeObject["object"].(string)
-			if !ok {
-				return errors.New("Invalid json")
+			// The metadata names the exact compiler build
+			var metadata struct {
+				Compiler struct {
+					Version string `json:"version"`
+				} `json:"compiler"`
 			}
-
-			// Get link references as a json blob
-			linkReferences, err := json.Marshal(bytecodeObject["linkReferences"])
-			if err != nil {
-				return err
+			if json.Unmarshal([]byte(a.Metadata), &metadata) == nil && metadata.Compiler.Version != "" {
+				a.Compiler = metadata.Compiler.Version
 			}
 
-			ioutil.WriteFile(filepath.Join(project.BuildDirectory, name+".abi"), abi, 0644)
-			ioutil.WriteFile(filepath.Join(project.BuildDirectory, name+".bin"), []byte(bytecode), 0644)
-			ioutil.WriteFile(filepath.Join(project.BuildDirectory, name+".link"), linkReferences, 0644)
+			saved = append(saved, a)
+		}
+	}
+
+	return saved, nil
+}
+
+// sourceIndex lists the sources of a compile by the ids source maps refer to
+// them by
+func sourceIndex(units map[string]interface{}) []string {
+	list := make([]string, 0, len(units))
+	for name, value := range units {
+		unit, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		id, ok := unit["id"].(float64)
+		if !ok || id < 0 || int(id) >= len(units) {
+			continue
+		}
+
+		for len(list) <= int(id) {
+			list = append(list, "")
 		}
+		list[int(id)] = name
 	}
 
-	return nil
+	return list
 }
 
-func generateBindings() error {
-	matches, err := filepath.Glob(project.BuildDirectory + "/*.abi")
+// parseArtifact reads the compiler output for a single contract
+func parseArtifact(data map[string]interface{}) (*artifact, error) {
+	a := &artifact{}
+
+	// Get ABI as a json blob
+	abi, err := json.Marshal(data["abi"])
 	if err != nil {
-		return err
+		return nil, err
 	}
+	a.ABI = abi
 
-	if err := os.MkdirAll(project.BindingsDirectory, os.FileMode(0755)); err != nil {
-		return err
+	a.Metadata, _ = data["metadata"].(string)
+
+	// NatSpec is output as objects, which are saved as they are
+	for key, field := range map[string]*json.RawMessage{"userdoc": &a.Userdoc, "devdoc": &a.Devdoc} {
+		if doc, ok := data[key]; ok && doc != nil {
+			if *field, err = json.Marshal(doc); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	for _, match := range matches {
-		if err := generateBinding(strings.TrimSuffix(match, filepath.Ext(match))); err != nil {
-			return err
+	// Compilers before 0.5.13 have no storage layout
+	if layout, ok := data["storageLayout"]; ok && layout != nil {
+		if a.StorageLayout, err = json.Marshal(layout); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	evm, ok := data["evm"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Invalid json")
+	}
+
+	bytecode, ok := evm["bytecode"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Invalid json")
+	}
+
+	if a.Bytecode, ok = bytecode["object"].(string); !ok {
+		return nil, errors.New("Invalid json")
+	}
+	// vyper prefixes its bytecode, solc doesn't
+	a.Bytecode = strings.TrimPrefix(a.Bytecode, "0x")
+	a.SourceMap, _ = bytecode["sourceMap"].(string)
+
+	if a.LinkReferences, err = parseLinkReferences(bytecode["linkReferences"]); err != nil {
+		return nil, err
+	}
+
+	// Older compilers don't produce everything, so the rest is optional
+	if deployed, ok := evm["deployedBytecode"].(map[string]interface{}); ok {
+		a.DeployedBytecode, _ = deployed["object"].(string)
+		a.DeployedBytecode = strings.TrimPrefix(a.DeployedBytecode, "0x")
+		a.DeployedSourceMap, _ = deployed["sourceMap"].(string)
+
+		if a.DeployedLinkReferences, err = parseLinkReferences(deployed["linkReferences"]); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
 }
 
-func generateBinding(path string) error {
-	// TODO: Allow alternate binding directories / package names, in config file
-	command := "abigen"
-	_, err := exec.LookPath(command)
+func parseLinkReferences(value interface{}) (linkReferences, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
 	if err != nil {
-		return errors.New("Can't locate abigen, is it installed and in your path")
+		return nil, err
+	}
+
+	var refs linkReferences
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+// linkedLibraries returns the qualified names of every library linked by the
+// contracts a job owns, directly or through other libraries
+func linkedLibraries(contracts map[string]interface{}, job *compileJob) map[string]bool {
+	libraries := make(map[string]bool)
+
+	var visit func(source, name string)
+	visit = func(source, name string) {
+		contract, _ := contracts[source].(map[string]interface{})
+		data, _ := contract[name].(map[string]interface{})
+		evm, _ := data["evm"].(map[string]interface{})
+		bytecode, _ := evm["bytecode"].(map[string]interface{})
+		refs, _ := bytecode["linkReferences"].(map[string]interface{})
+
+		for file, value := range refs {
+			names, _ := value.(map[string]interface{})
+			for library := range names {
+				if !libraries[file+":"+library] {
+					libraries[file+":"+library] = true
+					visit(file, library)
+				}
+			}
+		}
+	}
+
+	for source, value := range contracts {
+		contract, _ := value.(map[string]interface{})
+		for name := range contract {
+			if job.owns(source, name) {
+				visit(source, name)
+			}
+		}
 	}
 
-	name := filepath.Base(path)
-	abifile := path + ".abi"
-	binfile := path + ".bin"
-	outfile := filepath.Join(project.BindingsDirectory, filepath.Base(name)) + ".go"
-	args := []string{"--abi", abifile, "--bin", binfile, "--pkg", "bindings", "--type", name, "--out", outfile}
-	return ExecWithOutput(command, args...)
+	return libraries
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// failingSolc is a solc that reports a parser error in every source
+const failingSolc = `#!/bin/sh
+if [ "$1" = "--version" ]; then
+	echo "Version: 0.8.19+commit.7dd6d404.Linux.g++"
+	exit 0
+fi
+cat > /dev/null
+echo '{"errors":[{"severity":"error","type":"ParserError","errorCode":"2314","message":"Expected ;","formattedMessage":"ParserError: Expected ;"}]}'
+`
+
+func TestCompileMachineReadableFailure(t *testing.T) {
+	if os.Getenv("WB_TEST_COMPILE") != "" {
+		RootCmd.SetArgs([]string{"compile", "--format", os.Getenv("WB_TEST_COMPILE")})
+		Execute()
+		os.Exit(0)
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake compiler is a shell script")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"wb.yaml":               "project: example.com/token\n",
+		"contracts/Token.sol":   "pragma solidity ^0.8.0;\ncontract Token {}\n",
+		"compilers/0.8.19/solc": failingSolc,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, format := range []string{"json", "sarif"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := exec.Command(os.Args[0], "-test.run=^TestCompileMachineReadableFailure$")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "WB_TEST_COMPILE="+format, "WB_COMPILERS="+filepath.Join(dir, "compilers"))
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+			err := cmd.Run()
+			if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 1 {
+				t.Fatalf("compile exited with %v, want exit status 1\nstdout: %s\nstderr: %s", err, &stdout, &stderr)
+			}
+
+			if !json.Valid(stdout.Bytes()) {
+				t.Errorf("stdout isn't valid json:\n%s", &stdout)
+			}
+			if !strings.Contains(stderr.String(), "Error detected") {
+				t.Errorf("stderr = %q, want the compile error", &stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var compilerCmd = &cobra.Command{
+	Use:   "compiler",
+	Short: "Manage the compiler versions available to the project",
+}
+
+var compilerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed compiler versions",
+	Run: func(cmd *cobra.Command, args []string) {
+		compilers, err := installedCompilers()
+		if err != nil {
+			Fatal(err)
+		}
+
+		if len(compilers) == 0 {
+			fmt.Println("No compilers installed, add one with `wb compiler install --from PATH`")
+			return
+		}
+
+		for _, compiler := range compilers {
+			marker := " "
+			if compiler.Version.String() == defaultCompilerVersion(compiler.Backend) {
+				marker = "*"
+			}
+
+			fmt.Printf("%s %-6s %-10s %s\n", marker, compiler.Backend.Name(), compiler.Version, compiler.Path)
+		}
+	},
+}
+
+var compilerInstallCmd = &cobra.Command{
+	Use:   "install [VERSION]",
+	Short: "Register a downloaded compiler binary in the local compiler store",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			Fatal("please provide only one version")
+		}
+
+		backend := compilerLanguage(cmd)
+
+		from, _ := cmd.Flags().GetString("from")
+		if from == "" {
+			Fatal("Must specify the compiler binary to install with --from")
+		}
+
+		reported, err := backend.version(from)
+		if err != nil {
+			Fatal(err)
+		}
+
+		v, err := parseVersion(reported)
+		if err != nil {
+			Fatal(err)
+		}
+
+		if len(args) == 1 && args[0] != v.String() {
+			Fatal(fmt.Sprintf("%s reports version %s, not %s", from, v, args[0]))
+		}
+
+		path := filepath.Join(compilerStoreDirectory(), v.String(), backend.Name())
+		if err := copyFile(from, path, os.FileMode(0755)); err != nil {
+			Fatal(err)
+		}
+
+		fmt.Println("Installed", backend.Name(), v, "at", path)
+	},
+}
+
+var compilerUseCmd = &cobra.Command{
+	Use:   "use VERSION",
+	Short: "Prefer an installed compiler version when several satisfy a pragma",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			Fatal("Must specify compiler version")
+		}
+
+		backend := compilerLanguage(cmd)
+
+		v, err := parseVersion(args[0])
+		if err != nil {
+			Fatal(err)
+		}
+
+		if _, err := os.Stat(filepath.Join(compilerStoreDirectory(), v.String(), backend.Name())); err != nil {
+			Fatal(fmt.Sprintf("%s %s is not installed, add it with `wb compiler install --from PATH`", backend.Name(), v))
+		}
+
+		path := filepath.Join(compilerStoreDirectory(), defaultCompilerFilename(backend))
+		if err := ioutil.WriteFile(path, []byte(v.String()+"\n"), 0644); err != nil {
+			Fatal(err)
+		}
+
+		fmt.Println("Using", backend.Name(), v, "by default")
+	},
+}
+
+func init() {
+	compilerCmd.AddCommand(compilerListCmd)
+	compilerCmd.AddCommand(compilerInstallCmd)
+	compilerCmd.AddCommand(compilerUseCmd)
+	RootCmd.AddCommand(compilerCmd)
+
+	compilerCmd.PersistentFlags().String("lang", "solidity", "language of the compiler: solidity or vyper")
+	compilerInstallCmd.Flags().String("from", "", "path to the compiler binary to install")
+}
+
+// compilerLanguage returns the backend selected by the --lang flag
+func compilerLanguage(cmd *cobra.Command) compilerBackend {
+	language, _ := cmd.Flags().GetString("lang")
+
+	backend, err := backendForLanguage(language)
+	if err != nil {
+		Fatal(err)
+	}
+
+	return backend
+}
+
+// installedCompiler is a compiler binary of a known version
+type installedCompiler struct {
+	Backend compilerBackend
+	Version version
+	Path    string
+}
+
+func (c *installedCompiler) String() string {
+	return fmt.Sprintf("%s %s", c.Backend.Name(), c.Version)
+}
+
+// compilerStoreDirectory is where installed compilers live, one directory per
+// version, e.g. ~/.wb/compilers/0.4.24/solc or ~/.wb/compilers/0.3.10/vyper
+func compilerStoreDirectory() string {
+	if dir := os.Getenv("WB_COMPILERS"); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		Fatal(err)
+	}
+
+	return filepath.Join(home, ".wb", "compilers")
+}
+
+// defaultCompilerFilename is where the default version of a compiler is kept.
+// solc's predates there being other compilers
+func defaultCompilerFilename(backend compilerBackend) string {
+	if backend.Name() == "solc" {
+		return "default"
+	}
+
+	return "default-" + backend.Name()
+}
+
+func defaultCompilerVersion(backend compilerBackend) string {
+	data, err := ioutil.ReadFile(filepath.Join(compilerStoreDirectory(), defaultCompilerFilename(backend)))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// installedCompilers returns every compiler in the local store, newest first,
+// followed by those on the path if they're versions not already in the store
+func installedCompilers() ([]*installedCompiler, error) {
+	compilers := make([]*installedCompiler, 0)
+	seen := make(map[string]bool)
+
+	entries, err := ioutil.ReadDir(compilerStoreDirectory())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		v, err := parseVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		for _, backend := range backends {
+			path := filepath.Join(compilerStoreDirectory(), entry.Name(), backend.Name())
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			compiler := &installedCompiler{Backend: backend, Version: v, Path: path}
+			compilers = append(compilers, compiler)
+			seen[compiler.String()] = true
+		}
+	}
+
+	sort.Slice(compilers, func(i, j int) bool {
+		return compilers[i].Version.compare(compilers[j].Version) > 0
+	})
+
+	for _, backend := range backends {
+		path, err := exec.LookPath(backend.Name())
+		if err != nil {
+			continue
+		}
+
+		if reported, err := backend.version(path); err == nil {
+			if v, err := parseVersion(reported); err == nil {
+				compiler := &installedCompiler{Backend: backend, Version: v, Path: path}
+				if !seen[compiler.String()] {
+					compilers = append(compilers, compiler)
+				}
+			}
+		}
+	}
+
+	return compilers, nil
+}
+
+// selectCompilers picks a compiler for every source that satisfies the pragmas
+// of the source and everything it imports, preferring the default version of
+// the source's language and then the newest
+func selectCompilers(sources sourceSet, compilers []*installedCompiler) (map[string]*installedCompiler, error) {
+	candidates := make(map[string][]*installedCompiler)
+	for _, compiler := range compilers {
+		name := compiler.Backend.Name()
+		if compiler.Version.String() == defaultCompilerVersion(compiler.Backend) {
+			candidates[name] = append([]*installedCompiler{compiler}, candidates[name]...)
+		} else {
+			candidates[name] = append(candidates[name], compiler)
+		}
+	}
+
+	selected := make(map[string]*installedCompiler)
+	for _, name := range sources.names() {
+		backend := sources[name].Backend
+		if len(candidates[backend.Name()]) == 0 {
+			return nil, fmt.Errorf("Can't locate %s for %s, install one with `wb compiler install --lang %s --from PATH` or add it to your path", backend.Name(), name, strings.ToLower(backend.Language()))
+		}
+
+		constraints := make([]constraint, 0)
+		pragmas := make([]string, 0)
+		for _, dep := range sources.closure([]string{name}) {
+			for _, pragma := range sources[dep].Pragmas {
+				c, err := parseConstraint(pragma)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid pragma %q: %v", dep, pragma, err)
+				}
+
+				constraints = append(constraints, c)
+				pragmas = append(pragmas, fmt.Sprintf("%s (%s)", pragma, dep))
+			}
+		}
+
+		for _, compiler := range candidates[backend.Name()] {
+			ok := true
+			for _, c := range constraints {
+				if !c.matches(compiler.Version) {
+					ok = false
+					break
+				}
+			}
+
+			if ok {
+				selected[name] = compiler
+				break
+			}
+		}
+
+		if selected[name] == nil {
+			return nil, fmt.Errorf("No installed %s satisfies the pragmas for %s: %s", backend.Name(), name, strings.Join(pragmas, ", "))
+		}
+	}
+
+	return selected, nil
+}
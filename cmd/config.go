@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zscole/cli/project"
+)
+
+// editConfig rewrites wb.yaml with the changes edit makes to its document.
+// The file is edited as a yaml.v3 node tree rather than through viper, so
+// comments and the order of keys are kept. Run in the project root
+func editConfig(edit func(root *yaml.Node) error) error {
+	if err := writeEditedConfig(project.ProjectConfigFilename, project.ProjectConfigFilename, edit); err != nil {
+		return err
+	}
+
+	// Later steps of the same command see the new config
+	return viper.ReadInConfig()
+}
+
+// writeEditedConfig writes the config in one file, with the changes edit
+// makes to it, to another or the same file
+func writeEditedConfig(from, to string, edit func(root *yaml.Node) error) error {
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %v", from, err)
+	}
+
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: must be a mapping", from)
+	}
+
+	if err := edit(root); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(to, spaceSections(buf.Bytes()), 0644)
+}
+
+// spaceSections puts back the blank lines between top level sections, and the
+// comments above them, that yaml.v3 drops. Top level scalars, such as the
+// project and license, stay together
+func spaceSections(data []byte) []byte {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	spaced := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i > 0 && topLevelLine(line) && !strings.HasPrefix(lines[i-1], "#") &&
+			!(topLevelScalar(lines[i-1]) && topLevelScalar(line)) {
+			spaced = append(spaced, "")
+		}
+		spaced = append(spaced, line)
+	}
+
+	return []byte(strings.Join(spaced, "\n") + "\n")
+}
+
+func topLevelLine(line string) bool {
+	return line != "" && line[0] != ' ' && line[0] != '-'
+}
+
+// topLevelScalar is whether a line is a top level key with a value on the
+// same line, rather than a section or a comment
+func topLevelScalar(line string) bool {
+	return topLevelLine(line) && line[0] != '#' && !strings.HasSuffix(strings.TrimSpace(line), ":")
+}
+
+// configIndex returns the index of a key in a mapping node, or -1. Keys are
+// matched ignoring case, as viper does
+func configIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// configMapping returns the mapping under a key of a mapping node, adding it
+// if there's none
+func configMapping(mapping *yaml.Node, key string) (*yaml.Node, error) {
+	if i := configIndex(mapping, key); i >= 0 {
+		value := mapping.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			*value = yaml.Node{Kind: yaml.MappingNode}
+		}
+
+		if value.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: must be a mapping", key)
+		}
+
+		return value, nil
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content, configScalar("!!str", key), value)
+	return value, nil
+}
+
+// setConfig sets a key of a mapping node to a scalar, adding it if there's
+// none
+func setConfig(mapping *yaml.Node, key, tag, value string) {
+	if i := configIndex(mapping, key); i >= 0 {
+		mapping.Content[i+1] = configScalar(tag, value)
+		return
+	}
+
+	mapping.Content = append(mapping.Content, configScalar("!!str", key), configScalar(tag, value))
+}
+
+// removeConfig removes a key from a mapping node, returning whether it was
+// there
+func removeConfig(mapping *yaml.Node, key string) bool {
+	i := configIndex(mapping, key)
+	if i < 0 {
+		return false
+	}
+
+	mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+	return true
+}
+
+// copyConfig returns a deep copy of a node
+func copyConfig(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, copyConfig(child))
+	}
+
+	return &copied
+}
+
+func configScalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
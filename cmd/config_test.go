@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testConfig = `project: token
+license: MIT
+
+# Networks migrations run on
+networks:
+    dev:
+        url: http://localhost:8545 # local node
+        keystore: keystore
+    empty:
+
+compiler:
+    version: 0.8.19
+`
+
+func TestWriteEditedConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(root *yaml.Node) error
+		want string
+		err  bool
+	}{
+		{
+			name: "unchanged",
+			edit: func(root *yaml.Node) error { return nil },
+			want: testConfig,
+		},
+		{
+			name: "set a key",
+			edit: func(root *yaml.Node) error {
+				networks, err := configMapping(root, "networks")
+				if err != nil {
+					return err
+				}
+
+				dev, err := configMapping(networks, "dev")
+				if err != nil {
+					return err
+				}
+
+				setConfig(dev, "chain_id", "!!int", "1337")
+				return nil
+			},
+			want: `project: token
+license: MIT
+
+# Networks migrations run on
+networks:
+    dev:
+        url: http://localhost:8545 # local node
+        keystore: keystore
+        chain_id: 1337
+    empty:
+
+compiler:
+    version: 0.8.19
+`,
+		},
+		{
+			name: "add a network to an empty mapping",
+			edit: func(root *yaml.Node) error {
+				networks, err := configMapping(root, "networks")
+				if err != nil {
+					return err
+				}
+
+				empty, err := configMapping(networks, "EMPTY")
+				if err != nil {
+					return err
+				}
+
+				setConfig(empty, "url", "!!str", "http://localhost:7545")
+				return nil
+			},
+			want: `project: token
+license: MIT
+
+# Networks migrations run on
+networks:
+    dev:
+        url: http://localhost:8545 # local node
+        keystore: keystore
+    empty:
+        url: http://localhost:7545
+
+compiler:
+    version: 0.8.19
+`,
+		},
+		{
+			name: "add and remove sections",
+			edit: func(root *yaml.Node) error {
+				if !removeConfig(root, "Compiler") {
+					return errors.New("no compiler section")
+				}
+
+				bindings, err := configMapping(root, "bindings")
+				if err != nil {
+					return err
+				}
+
+				setConfig(bindings, "package", "!!str", "contracts")
+				return nil
+			},
+			want: `project: token
+license: MIT
+
+# Networks migrations run on
+networks:
+    dev:
+        url: http://localhost:8545 # local node
+        keystore: keystore
+    empty:
+
+bindings:
+    package: contracts
+`,
+		},
+		{
+			name: "copy a network",
+			edit: func(root *yaml.Node) error {
+				networks, err := configMapping(root, "networks")
+				if err != nil {
+					return err
+				}
+
+				dev, err := configMapping(networks, "dev")
+				if err != nil {
+					return err
+				}
+
+				copied := copyConfig(dev)
+				setConfig(copied, "url", "!!str", "http://localhost:9545")
+				networks.Content = append(networks.Content, configScalar("!!str", "fork"), copied)
+				return nil
+			},
+			want: `project: token
+license: MIT
+
+# Networks migrations run on
+networks:
+    dev:
+        url: http://localhost:8545 # local node
+        keystore: keystore
+    empty:
+    fork:
+        url: http://localhost:9545
+        keystore: keystore
+
+compiler:
+    version: 0.8.19
+`,
+		},
+		{
+			name: "mapping under a scalar",
+			edit: func(root *yaml.Node) error {
+				_, err := configMapping(root, "project")
+				return err
+			},
+			err: true,
+		},
+		{
+			name: "edit fails",
+			edit: func(root *yaml.Node) error { return errors.New("failed") },
+			err:  true,
+		},
+	}
+
+	dir := t.TempDir()
+	from := filepath.Join(dir, "wb.yaml")
+	if err := ioutil.WriteFile(from, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			to := filepath.Join(dir, "edited.yaml")
+			err := writeEditedConfig(from, to, test.edit)
+			if (err != nil) != test.err {
+				t.Fatalf("writeEditedConfig() error = %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := ioutil.ReadFile(to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("writeEditedConfig() wrote\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteEditedConfigEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wb.yaml")
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeEditedConfig(path, path, func(root *yaml.Node) error {
+		setConfig(root, "project", "!!str", "token")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "project: token\n" {
+		t.Errorf("wrote %q, want %q", got, "project: token\n")
+	}
+}
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var diagnosticFormats = []string{"human", "json", "sarif"}
+
+// diagnostic is an error, warning or info reported by the compiler
+type diagnostic struct {
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+	Severity  string `json:"severity"`
+	Code      string `json:"code,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Message   string `json:"message"`
+	Compiler  string `json:"compiler"`
+
+	// Fatal is set for errors, and for warnings if they're treated as errors
+	Fatal bool `json:"fatal"`
+
+	formatted string
+	backend   compilerBackend
+}
+
+// parseDiagnostics reads the errors list of the compiler output, dropping any
+// with a suppressed code. solc leaves the list out entirely when there's
+// nothing to report
+func parseDiagnostics(output map[string]interface{}, sources sourceSet, compiler *installedCompiler, config *compilerConfig) ([]diagnostic, error) {
+	value, ok := output["errors"]
+	if !ok {
+		return nil, nil
+	}
+
+	compilerErrors, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New("Invalid json: errors is not a list")
+	}
+
+	diagnostics := make([]diagnostic, 0, len(compilerErrors))
+	for i, value := range compilerErrors {
+		compilerErr, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Invalid json: errors[%d] is not an object", i)
+		}
+
+		severity, ok := compilerErr["severity"].(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid json: errors[%d] has no severity", i)
+		}
+
+		message, ok := compilerErr["message"].(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid json: errors[%d] has no message", i)
+		}
+
+		d := diagnostic{
+			Severity: severity,
+			Message:  message,
+			Compiler: compiler.Version.String(),
+			backend:  compiler.Backend,
+		}
+		d.Code, _ = compilerErr["errorCode"].(string)
+		d.Type, _ = compilerErr["type"].(string)
+		d.formatted, _ = compilerErr["formattedMessage"].(string)
+
+		if location, ok := compilerErr["sourceLocation"].(map[string]interface{}); ok {
+			d.locate(location, sources)
+		}
+
+		// Errors can't be suppressed, since nothing would be built
+		if severity != "error" && d.Code != "" && contains(config.SuppressedCodes, d.Code) {
+			continue
+		}
+
+		d.Fatal = severity == "error" || (severity == "warning" && config.WarningsAsErrors)
+		diagnostics = append(diagnostics, d)
+	}
+
+	return diagnostics, nil
+}
+
+// locate resolves the byte offsets of a solc source location, or the line and
+// column of a vyper one, to lines and columns in the source
+func (d *diagnostic) locate(location map[string]interface{}, sources sourceSet) {
+	file, _ := location["file"].(string)
+	if file == "" {
+		return
+	}
+	d.File = file
+
+	source, ok := sources[file]
+	if !ok {
+		return
+	}
+	d.File = filepath.ToSlash(source.Path)
+
+	if line, ok := location["lineno"].(float64); ok {
+		column, _ := location["col_offset"].(float64)
+		d.Line, d.Column = int(line), int(column)+1
+		return
+	}
+
+	start, ok := location["start"].(float64)
+	if !ok || start < 0 {
+		return
+	}
+	d.Line, d.Column = lineColumn(source.Content, int(start))
+
+	if end, ok := location["end"].(float64); ok && end >= start {
+		d.EndLine, d.EndColumn = lineColumn(source.Content, int(end))
+	}
+}
+
+// lineColumn returns the 1-based line and column of a byte offset
+func lineColumn(content []byte, offset int) (int, int) {
+	if offset > len(content) {
+		offset = len(content)
+	}
+
+	line, column := 1, 1
+	for _, b := range content[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return line, column
+}
+
+func (d diagnostic) String() string {
+	if d.formatted != "" {
+		return strings.TrimRight(d.formatted, "\n")
+	}
+
+	kind := d.Type
+	if kind == "" {
+		kind = d.Severity
+	}
+
+	if d.File == "" {
+		return fmt.Sprintf("%s: %s", kind, d.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, kind, d.Message)
+}
+
+// diagnosticReporter prints diagnostics in the requested format, human output
+// as it arrives and the machine readable formats as a single document once
+// compiling is done
+type diagnosticReporter struct {
+	format      string
+	out         io.Writer
+	diagnostics []diagnostic
+
+	// seen holds the diagnostics already reported, since a file compiled in
+	// more than one job gets the same ones from each
+	seen map[diagnosticKey]bool
+}
+
+// diagnosticKey identifies a diagnostic regardless of the job reporting it
+type diagnosticKey struct {
+	file         string
+	line, column int
+	code         string
+	message      string
+}
+
+func newDiagnosticReporter(format string) (*diagnosticReporter, error) {
+	if !contains(diagnosticFormats, format) {
+		return nil, fmt.Errorf("Unknown format %q, must be one of %s", format, strings.Join(diagnosticFormats, ", "))
+	}
+
+	return &diagnosticReporter{format: format, out: os.Stdout, diagnostics: make([]diagnostic, 0), seen: make(map[diagnosticKey]bool)}, nil
+}
+
+// progress is where status messages go, out of the way of machine readable
+// output
+func (r *diagnosticReporter) progress() io.Writer {
+	return progressOutput(r.format)
+}
+
+// progressOutput is where status messages and errors go for a diagnostic
+// format
+func progressOutput(format string) io.Writer {
+	if format == "human" {
+		return os.Stdout
+	}
+
+	return os.Stderr
+}
+
+func (r *diagnosticReporter) report(diagnostics []diagnostic) {
+	for _, d := range diagnostics {
+		key := diagnosticKey{d.File, d.Line, d.Column, d.Code, d.Message}
+		if r.seen[key] {
+			continue
+		}
+		r.seen[key] = true
+
+		r.diagnostics = append(r.diagnostics, d)
+		if r.format == "human" {
+			fmt.Fprintln(r.out, d)
+		}
+	}
+}
+
+func (r *diagnosticReporter) fatal() int {
+	count := 0
+	for _, d := range r.diagnostics {
+		if d.Fatal {
+			count++
+		}
+	}
+
+	return count
+}
+
+// flush writes the machine readable document, if any
+func (r *diagnosticReporter) flush() error {
+	var document interface{}
+	switch r.format {
+	case "json":
+		document = r.diagnostics
+	case "sarif":
+		document = sarifLog(r.diagnostics)
+	default:
+		return nil
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(r.out, string(data))
+	return err
+}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifLog builds a SARIF 2.1.0 log with a run for each compiler version
+func sarifLog(diagnostics []diagnostic) map[string]interface{} {
+	byCompiler := make(map[string][]diagnostic)
+	for _, d := range diagnostics {
+		key := d.backend.Name() + " " + d.Compiler
+		byCompiler[key] = append(byCompiler[key], d)
+	}
+
+	compilers := make([]string, 0, len(byCompiler))
+	for compiler := range byCompiler {
+		compilers = append(compilers, compiler)
+	}
+	sort.Strings(compilers)
+
+	runs := make([]interface{}, 0, len(compilers))
+	for _, compiler := range compilers {
+		backend, version := byCompiler[compiler][0].backend, byCompiler[compiler][0].Compiler
+
+		rules := make([]interface{}, 0)
+		seen := make(map[string]bool)
+		results := make([]interface{}, 0)
+
+		for _, d := range byCompiler[compiler] {
+			result := map[string]interface{}{
+				"level":   sarifLevel(d),
+				"message": map[string]interface{}{"text": d.Message},
+			}
+
+			if d.Code != "" {
+				result["ruleId"] = d.Code
+				if !seen[d.Code] {
+					seen[d.Code] = true
+					rules = append(rules, map[string]interface{}{
+						"id":   d.Code,
+						"name": d.Type,
+					})
+				}
+			}
+
+			if d.File != "" {
+				region := map[string]interface{}{}
+				if d.Line > 0 {
+					region["startLine"] = d.Line
+					region["startColumn"] = d.Column
+				}
+				if d.EndLine > 0 {
+					region["endLine"] = d.EndLine
+					region["endColumn"] = d.EndColumn
+				}
+
+				physical := map[string]interface{}{
+					"artifactLocation": map[string]interface{}{"uri": d.File},
+				}
+				if len(region) > 0 {
+					physical["region"] = region
+				}
+
+				result["locations"] = []interface{}{
+					map[string]interface{}{"physicalLocation": physical},
+				}
+			}
+
+			results = append(results, result)
+		}
+
+		runs = append(runs, map[string]interface{}{
+			"tool": map[string]interface{}{
+				"driver": map[string]interface{}{
+					"name":           backend.Name(),
+					"version":        version,
+					"informationUri": backend.Homepage(),
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		})
+	}
+
+	return map[string]interface{}{
+		"$schema": sarifSchema,
+		"version": sarifVersion,
+		"runs":    runs,
+	}
+}
+
+func sarifLevel(d diagnostic) string {
+	switch {
+	case d.Fatal:
+		return "error"
+	case d.Severity == "warning":
+		return "warning"
+	default:
+		return "note"
+	}
+}
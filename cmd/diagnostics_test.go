@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReportDedupesJobs(t *testing.T) {
+	unused := diagnostic{File: "contracts/lib/Math.sol", Line: 4, Column: 9, Severity: "warning", Code: "2072", Message: "Unused local variable.", backend: solcBackend{}}
+	shadowed := diagnostic{File: "contracts/lib/Math.sol", Line: 7, Column: 9, Severity: "warning", Code: "2519", Message: "This declaration shadows an existing declaration.", backend: solcBackend{}}
+	elsewhere := unused
+	elsewhere.File = "contracts/Token.sol"
+
+	// Math.sol is imported by files compiled in two jobs, and compiled again
+	// in a job of its own
+	jobs := [][]diagnostic{
+		{unused, shadowed},
+		{unused, shadowed, elsewhere},
+		{unused},
+	}
+
+	for _, format := range []string{"human", "json"} {
+		t.Run(format, func(t *testing.T) {
+			reporter, err := newDiagnosticReporter(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			reporter.out = &out
+
+			for _, diagnostics := range jobs {
+				reporter.report(diagnostics)
+			}
+			if err := reporter.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(reporter.diagnostics) != 3 {
+				t.Errorf("reported %d diagnostics, want 3", len(reporter.diagnostics))
+			}
+
+			if format == "human" {
+				if lines := strings.Count(out.String(), "\n"); lines != 3 {
+					t.Errorf("printed %d lines, want 3:\n%s", lines, &out)
+				}
+				return
+			}
+
+			var document []diagnostic
+			if err := json.Unmarshal(out.Bytes(), &document); err != nil {
+				t.Fatal(err)
+			}
+			if len(document) != 3 {
+				t.Errorf("document has %d diagnostics, want 3:\n%s", len(document), &out)
+			}
+		})
+	}
+}
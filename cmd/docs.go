@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zscole/cli/templates"
+)
+
+// docsFormats maps each format of `wb docs` to its file extension and the
+// name of its index
+var docsFormats = map[string][2]string{
+	"markdown": {".md", "README.md"},
+	"html":     {".html", "index.html"},
+}
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate reference documentation for the contracts from their NatSpec",
+	Long: `Generates a page for every built contract, listing its functions, events,
+errors and modifiers with their signatures, selectors and NatSpec comments,
+along with an index of the contracts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		if err := RunInRoot(func() error { return generateDocs(format, output) }); err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(docsCmd)
+
+	docsCmd.Flags().String("format", "markdown", "format of the documentation: markdown or html")
+	docsCmd.Flags().String("output", "docs", "directory to write the documentation to")
+}
+
+// contractDocs is the documentation of a contract, as rendered by the docs
+// templates
+type contractDocs struct {
+	Name        string
+	Title       string
+	Author      string
+	Notice      string
+	Details     string
+	Constructor *memberDocs
+	Functions   []memberDocs
+	Events      []memberDocs
+	Errors      []memberDocs
+	Modifiers   []memberDocs
+}
+
+type memberDocs struct {
+	Kind        string
+	Name        string
+	Declaration string
+	Selector    string
+	Notice      string
+	Details     string
+	Params      []paramDocs
+	Returns     []paramDocs
+}
+
+type paramDocs struct {
+	Name string
+	Type string
+	Doc  string
+}
+
+func generateDocs(format, output string) error {
+	formatInfo, ok := docsFormats[format]
+	if !ok {
+		return fmt.Errorf("Unknown format %q, must be markdown or html", format)
+	}
+
+	names, err := artifactNames()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return errors.New("No contracts built, build them with `wb compile`")
+	}
+
+	if err := os.MkdirAll(output, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	ext := formatInfo[0]
+	contracts := make([]*contractDocs, 0, len(names))
+	for _, name := range names {
+		docs, err := documentContract(name)
+		if err != nil {
+			return err
+		}
+		contracts = append(contracts, docs)
+
+		page, err := templates.ExecuteTemplate("docs/contract"+ext+".tpl", map[string]interface{}{"contract": docs})
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(output, name+ext), page.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+
+	index, err := templates.ExecuteTemplate("docs/index"+ext+".tpl", map[string]interface{}{"contracts": contracts, "ext": ext})
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(output, formatInfo[1]), index.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	fmt.Println("Documented", len(contracts), "contracts in", output)
+	return nil
+}
+
+// documentContract gathers the documentation of a contract from its abi and
+// NatSpec output, and its modifiers from its source
+func documentContract(name string) (*contractDocs, error) {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return nil, err
+	}
+
+	natspec, err := parseNatspec(a)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseABI(a.ABI)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	docs := &contractDocs{
+		Name:    name,
+		Title:   natspec.Devdoc.Title,
+		Author:  natspec.Devdoc.Author,
+		Notice:  natspec.Userdoc.Notice,
+		Details: natspec.Devdoc.Details,
+	}
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case "constructor":
+			// NatSpec keys the constructor by name rather than signature
+			member := documentMember(entry, natspec.method("constructor"))
+			member.Selector = ""
+			docs.Constructor = &member
+		case "function":
+			docs.Functions = append(docs.Functions, documentMember(entry, natspec.method(entry.signature())))
+		case "event":
+			docs.Events = append(docs.Events, documentMember(entry, natspec.event(entry.signature())))
+		case "error":
+			docs.Errors = append(docs.Errors, documentMember(entry, natspec.customError(entry.signature())))
+		}
+	}
+
+	content, err := contractSource(a)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, modifier := range sourceModifiers(content, name) {
+		params := make([]paramDocs, 0)
+		for _, param := range modifierParams(modifier.Signature) {
+			param.Doc = flatten(modifier.Entry.Params[param.Name])
+			params = append(params, param)
+		}
+
+		docs.Modifiers = append(docs.Modifiers, memberDocs{
+			Kind:        "modifier",
+			Name:        modifier.Name,
+			Declaration: "modifier " + modifier.Signature,
+			Notice:      modifier.Entry.Notice,
+			Details:     modifier.Entry.Details,
+			Params:      params,
+		})
+	}
+
+	return docs, nil
+}
+
+func documentMember(entry abiEntry, doc natspecEntry) memberDocs {
+	member := memberDocs{
+		Kind:        entry.Type,
+		Name:        entry.Name,
+		Declaration: declaration(entry),
+		Selector:    entry.selector(),
+		Notice:      doc.Notice,
+		Details:     doc.Details,
+	}
+
+	for _, input := range entry.Inputs {
+		member.Params = append(member.Params, paramDocs{Name: input.Name, Type: input.canonicalType(), Doc: flatten(doc.Params[input.Name])})
+	}
+
+	// Unnamed return values are documented by position
+	for i, output := range entry.Outputs {
+		text, ok := doc.Returns[output.Name]
+		if !ok || output.Name == "" {
+			text = doc.Returns[fmt.Sprintf("_%d", i)]
+		}
+		member.Returns = append(member.Returns, paramDocs{Name: output.Name, Type: output.canonicalType(), Doc: flatten(text)})
+	}
+
+	return member
+}
+
+// declaration is how an abi entry would be declared in solidity
+func declaration(entry abiEntry) string {
+	params := func(args []abiArgument) string {
+		list := make([]string, 0, len(args))
+		for _, arg := range args {
+			param := arg.canonicalType()
+			if arg.Indexed {
+				param += " indexed"
+			}
+			if arg.Name != "" {
+				param += " " + arg.Name
+			}
+			list = append(list, param)
+		}
+
+		return "(" + strings.Join(list, ", ") + ")"
+	}
+
+	if entry.Type == "constructor" {
+		return "constructor" + params(entry.Inputs)
+	}
+
+	decl := entry.Type + " " + entry.Name + params(entry.Inputs)
+	if entry.Type == "function" {
+		if entry.StateMutability != "" && entry.StateMutability != "nonpayable" {
+			decl += " " + entry.StateMutability
+		}
+		if len(entry.Outputs) > 0 {
+			decl += " returns " + params(entry.Outputs)
+		}
+	}
+	if entry.Anonymous {
+		decl += " anonymous"
+	}
+
+	return decl
+}
+
+// contractSource returns the source a contract was built from, which combined
+// artifacts include and split artifacts are found by through the manifest
+func contractSource(a *artifact) ([]byte, error) {
+	if a.Source != "" {
+		return []byte(a.Source), nil
+	}
+
+	entry, ok := loadBuildManifest().Contracts[a.ContractName]
+	if !ok || entry.Source == "" {
+		return nil, nil
+	}
+
+	config, err := loadCompilerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	path := locateSource(entry.Source, config.IncludePaths)
+	if path == "" {
+		return nil, nil
+	}
+
+	return ioutil.ReadFile(path)
+}
+
+// modifierParams reads the parameters of a modifier from its signature
+func modifierParams(signature string) []paramDocs {
+	start, end := strings.Index(signature, "("), strings.LastIndex(signature, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	params := make([]paramDocs, 0)
+	for _, param := range strings.Split(signature[start+1:end], ",") {
+		fields := strings.Fields(param)
+		if len(fields) < 2 {
+			continue
+		}
+
+		params = append(params, paramDocs{
+			Name: fields[len(fields)-1],
+			Type: strings.Join(fields[:len(fields)-1], " "),
+		})
+	}
+
+	return params
+}
+
+// flatten joins the lines of a NatSpec description, so it fits a table cell
+func flatten(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
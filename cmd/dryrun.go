@@ -0,0 +1,648 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zscole/cli/deployer"
+	"github.com/zscole/cli/deployments"
+	"github.com/zscole/cli/project"
+	"github.com/zscole/cli/simulated"
+)
+
+const (
+	// dryRunSuffix names the temporary network, registry and linked
+	// bytecode of a dry run after the network it stands in for
+	dryRunSuffix = "-dry-run"
+
+	// dryRunSlots is how many storage slots of each deployed contract are
+	// copied into a dry run. That covers the plain state variables of
+	// contracts like Migrations. Mappings and dynamic arrays are stored at
+	// hashed slots, which can't be listed, so a contract with any isn't
+	// forked
+	dryRunSlots = 16
+)
+
+// dryRun is a simulated chain standing in for a network, forked from its
+// state at a block
+type dryRun struct {
+	network  *networkConfig
+	name     string
+	block    *big.Int
+	chainID  *big.Int
+	gasPrice *big.Int
+	chain    *simulated.Chain
+
+	// deployer and balance are the account migrations deploy from and what
+	// it holds on the network, if the network has a keystore
+	deployer *common.Address
+	balance  *big.Int
+
+	mu   sync.Mutex
+	sent []sentTransaction
+
+	// missing counts the calls made to each address with no code on the
+	// fork, which may have a contract on the network that wasn't copied
+	missing map[common.Address]int
+}
+
+// sentTransaction is a transaction the stub sent, and when
+type sentTransaction struct {
+	hash common.Hash
+	time time.Time
+}
+
+// dryRunMigrations runs the pending migrations on a simulation of a network
+// forked from its latest block, and reports the gas they use, what they'd
+// cost and the contracts they'd deploy. Nothing is sent to the network.
+//
+// Keystore accounts keep their balance and nonce, so contracts get the
+// addresses they would on the network, and contracts already deployed keep
+// their code and storage. A contract whose storage can't all be copied stops
+// the dry run, unless it's reset. Anything else the migrations read from the
+// network isn't there, and calls they make straight to an address without
+// code are warned of
+func dryRunMigrations(name string, reset bool) error {
+	return RunInRoot(func() error {
+		network, err := loadNetworkConfig(name)
+		if err != nil {
+			return err
+		}
+
+		if network.simulated() {
+			return fmt.Errorf("Network %q is simulated, so has no state to dry run against", name)
+		}
+
+		fmt.Println("Forking network", name)
+		d, err := forkNetwork(network, reset)
+		if err != nil {
+			return err
+		}
+		defer d.chain.Close()
+
+		server, err := simulated.NewServer(d.chain)
+		if err != nil {
+			return err
+		}
+		defer server.Stop()
+		server.OnTransaction = d.record
+		server.OnCall = func(msg ethereum.CallMsg) { d.checkCode(msg.To, msg.Data) }
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+		go http.Serve(listener, server)
+
+		mirror, err := d.mirrorProject("http://" + listener.Addr().String())
+		if err != nil {
+			return err
+		}
+		defer d.cleanUp(mirror)
+
+		// An interrupt stops the stub, which shares the terminal, and the
+		// mirror is cleaned up once it has
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		defer signal.Stop(signals)
+
+		start := time.Now()
+		migrateErr := inMirror(mirror, func() error { return runMigrations(d.name, reset) })
+
+		registry, err := deployments.Load(".", d.name)
+		if err != nil {
+			return err
+		}
+
+		if err := d.report(registry, start); err != nil {
+			return err
+		}
+
+		if migrateErr != nil {
+			return fmt.Errorf("Dry run stopped: %v", migrateErr)
+		}
+
+		return nil
+	})
+}
+
+// forkNetwork starts a simulated chain with the state of the network the
+// migrations depend on. Contracts deployed by migrations are left out if
+// they're all about to be deployed again
+func forkNetwork(network *networkConfig, reset bool) (*dryRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, chainID, err := network.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dryRun{
+		network: network,
+		name:    network.Name + dryRunSuffix,
+		block:   header.Number,
+		chainID: chainID,
+		missing: make(map[common.Address]int),
+	}
+	if network.GasPrice != "" {
+		d.gasPrice, _ = gweiToWei(network.GasPrice)
+	} else if d.gasPrice, err = client.SuggestGasPrice(ctx); err != nil {
+		return nil, err
+	}
+
+	alloc := make(types.GenesisAlloc)
+	if network.Keystore != "" {
+		ks, err := network.keystore()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range ks.Accounts() {
+			balance, err := client.BalanceAt(ctx, account.Address, d.block)
+			if err != nil {
+				return nil, err
+			}
+
+			nonce, err := client.NonceAt(ctx, account.Address, d.block)
+			if err != nil {
+				return nil, err
+			}
+
+			alloc[account.Address] = types.Account{Balance: balance, Nonce: nonce}
+		}
+
+		if account, err := deployer.Select(ks.Accounts(), network.Deployer); err == nil {
+			d.deployer, d.balance = &account.Address, alloc[account.Address].Balance
+		}
+	}
+
+	registry, err := deployments.Load(".", network.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make(map[string]string)
+	if !reset {
+		for name, contract := range registry.Contracts {
+			contracts[name] = contract.Address
+		}
+	}
+	if err := d.forkContracts(ctx, client, alloc, contracts, true); err != nil {
+		return nil, err
+	}
+
+	// Libraries are only ever delegate called, so their code is all that's
+	// used
+	libraries := make(map[string]string)
+	for name, library := range registry.Libraries {
+		libraries[name] = library.Address
+	}
+	for name, address := range network.Libraries {
+		libraries[name] = address
+	}
+	if err := d.forkContracts(ctx, client, alloc, libraries, false); err != nil {
+		return nil, err
+	}
+
+	d.chain, err = simulated.New(simulated.Config{
+		ChainID:  chainID,
+		GasLimit: header.GasLimit,
+		Alloc:    alloc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// forkContracts adds the contracts at addresses, by name, to the genesis
+// accounts of the dry run
+func (d *dryRun) forkContracts(ctx context.Context, client *ethclient.Client, alloc types.GenesisAlloc, addresses map[string]string, withStorage bool) error {
+	for name, address := range addresses {
+		if !common.IsHexAddress(address) {
+			continue
+		}
+
+		account, err := forkContract(ctx, client, common.HexToAddress(address), d.block, withStorage)
+		if err != nil {
+			return fmt.Errorf("Forking %s: %v", name, err)
+		}
+
+		if account != nil {
+			alloc[common.HexToAddress(address)] = *account
+		}
+	}
+
+	return nil
+}
+
+// forkContract returns the genesis account of a deployed contract, or nil if
+// there's no code at the address. With its storage, the copy is checked
+// against the storage root the network proves, so that a contract is never
+// forked with part of its state
+func forkContract(ctx context.Context, client *ethclient.Client, address common.Address, block *big.Int, withStorage bool) (*types.Account, error) {
+	code, err := client.CodeAt(ctx, address, block)
+	if err != nil || len(code) == 0 {
+		return nil, err
+	}
+
+	balance, err := client.BalanceAt(ctx, address, block)
+	if err != nil {
+		return nil, err
+	}
+
+	if !withStorage {
+		return &types.Account{Code: code, Balance: balance}, nil
+	}
+
+	storage := make(map[common.Hash]common.Hash)
+	for i := int64(0); i < dryRunSlots; i++ {
+		key := common.BigToHash(big.NewInt(i))
+		value, err := client.StorageAt(ctx, address, key, block)
+		if err != nil {
+			return nil, err
+		}
+
+		if word := common.BytesToHash(value); word != (common.Hash{}) {
+			storage[key] = word
+		}
+	}
+
+	var proof struct {
+		StorageHash common.Hash `json:"storageHash"`
+	}
+	err = client.Client().CallContext(ctx, &proof, "eth_getProof", address, []string{}, hexutil.EncodeBig(block))
+	if err != nil {
+		return nil, fmt.Errorf("the network can't prove its storage, which a dry run checks is copied whole: %v", err)
+	}
+
+	if storageRoot(storage) != proof.StorageHash {
+		return nil, fmt.Errorf("it has storage beyond its first %d slots, such as a mapping, which a dry run can't copy. Dry run with --reset to deploy every contract afresh, though the gas reported then includes redeploying them", dryRunSlots)
+	}
+
+	return &types.Account{Code: code, Balance: balance, Storage: storage}, nil
+}
+
+// storageRoot returns the root of the trie an account with the storage would
+// have, as the state trie keys and encodes it
+func storageRoot(storage map[common.Hash]common.Hash) common.Hash {
+	keys := make([][]byte, 0, len(storage))
+	values := make(map[string][]byte, len(storage))
+	for slot, value := range storage {
+		key := crypto.Keccak256(slot[:])
+		encoded, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
+
+		keys = append(keys, key)
+		values[string(key)] = encoded
+	}
+
+	// A stack trie takes its keys in order
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	t := trie.NewStackTrie(nil)
+	for _, key := range keys {
+		t.Update(key, values[string(key)])
+	}
+
+	return t.Hash()
+}
+
+func (d *dryRun) record(tx *types.Transaction) {
+	d.checkCode(tx.To(), tx.Data())
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sent = append(d.sent, sentTransaction{hash: tx.Hash(), time: time.Now()})
+}
+
+// checkCode counts a call to an address with no code on the fork. Plain
+// transfers and calls to precompiles don't need any. Calls contracts make to
+// each other aren't seen
+func (d *dryRun) checkCode(to *common.Address, data []byte) {
+	if to == nil || len(data) == 0 || isPrecompile(*to) {
+		return
+	}
+
+	code, err := d.chain.PendingCodeAt(context.Background(), *to)
+	if err != nil || len(code) > 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.missing[*to]++
+}
+
+func isPrecompile(address common.Address) bool {
+	for _, precompile := range vm.PrecompiledAddressesOsaka {
+		if address == precompile {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mirrorProject makes a temporary directory mirroring the project root, for
+// the stub to migrate in. Everything is linked to the project except wb.yaml,
+// which is a copy with the network pointed at the fork under the dry run's
+// name, so the project's own config is never edited. The network's registry
+// is copied under the dry run's name too
+func (d *dryRun) mirrorProject(url string) (string, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// Anything the stub writes has to land in the project
+	for _, dir := range []string{project.BuildDirectory, project.DeploymentsDirectory} {
+		if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+			return "", err
+		}
+	}
+
+	// A dry run that was killed may have left its registry behind
+	os.Remove(deployments.Path(".", d.name))
+
+	registry, err := ioutil.ReadFile(deployments.Path(".", d.network.Name))
+	if err == nil {
+		err = ioutil.WriteFile(deployments.Path(".", d.name), registry, 0644)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	mirror, err := ioutil.TempDir("", "wb-dry-run-")
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := ioutil.ReadDir(".")
+	if err != nil {
+		os.RemoveAll(mirror)
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == project.ProjectConfigFilename {
+			continue
+		}
+
+		if err := os.Symlink(filepath.Join(root, entry.Name()), filepath.Join(mirror, entry.Name())); err != nil {
+			os.RemoveAll(mirror)
+			return "", err
+		}
+	}
+
+	err = writeEditedConfig(project.ProjectConfigFilename, filepath.Join(mirror, project.ProjectConfigFilename), func(root *yaml.Node) error {
+		networks, err := configMapping(root, "networks")
+		if err != nil {
+			return err
+		}
+
+		i := configIndex(networks, d.network.Name)
+		if i < 0 {
+			return fmt.Errorf("Unknown network %q, check the networks section of wb.yaml", d.network.Name)
+		}
+
+		network := copyConfig(networks.Content[i+1])
+		setConfig(network, "url", "!!str", url)
+
+		removeConfig(networks, d.name)
+		networks.Content = append(networks.Content, configScalar("!!str", d.name), network)
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(mirror)
+		return "", err
+	}
+
+	return mirror, nil
+}
+
+// inMirror runs f in a mirror of the project, with its config loaded in
+// place of the project's until f returns
+func inMirror(mirror string, f func() error) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chdir(mirror); err != nil {
+		return err
+	}
+	defer os.Chdir(wd)
+
+	config := viper.ConfigFileUsed()
+	defer func() {
+		viper.SetConfigFile(config)
+		viper.ReadInConfig()
+	}()
+
+	viper.SetConfigFile(filepath.Join(mirror, project.ProjectConfigFilename))
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return f()
+}
+
+// cleanUp removes the mirror, and what the dry run left in the project
+func (d *dryRun) cleanUp(mirror string) {
+	os.RemoveAll(mirror)
+	os.Remove(deployments.Path(".", d.name))
+	os.RemoveAll(filepath.Join(deployments.LinkedDirectory, d.name))
+}
+
+// dryRunGroup is the transactions of a migration, or of linking libraries
+// before any run
+type dryRunGroup struct {
+	label    string
+	count    int
+	gas      uint64
+	reverted []common.Hash
+}
+
+// report prints the gas each migration used, what it would cost and the
+// contracts it deployed, returning an error if any transaction reverted
+func (d *dryRun) report(registry *deployments.Registry, start time.Time) error {
+	d.mu.Lock()
+	sent := append([]sentTransaction{}, d.sent...)
+	d.mu.Unlock()
+
+	// Migrations are recorded as each finishes, so a transaction belongs to
+	// the first migration recorded after it was sent
+	ran := make([]deployments.Migration, 0)
+	for _, m := range registry.Migrations {
+		if m.Timestamp.After(start) {
+			ran = append(ran, m)
+		}
+	}
+	sort.Slice(ran, func(i, j int) bool { return ran[i].Timestamp.Before(ran[j].Timestamp) })
+
+	groups := []*dryRunGroup{{label: "Libraries"}}
+	byNumber := make(map[int]*dryRunGroup)
+	for _, m := range ran {
+		byNumber[m.Number] = &dryRunGroup{label: fmt.Sprintf("Migration %d", m.Number)}
+		groups = append(groups, byNumber[m.Number])
+	}
+	after := &dryRunGroup{label: "After the last migration"}
+	groups = append(groups, after)
+
+	// Libraries are deployed by the cli before the stub runs
+	libraries := make(map[string]bool)
+	for _, library := range registry.Libraries {
+		libraries[library.Transaction] = true
+	}
+
+	deployedLibraries := make(map[string]bool)
+	total := &dryRunGroup{label: "Total"}
+	for _, tx := range sent {
+		receipt, err := d.chain.TransactionReceipt(context.Background(), tx.hash)
+		if err != nil {
+			return err
+		}
+		if receipt == nil {
+			continue
+		}
+
+		group := after
+		for _, m := range ran {
+			if !m.Timestamp.Before(tx.time) {
+				group = byNumber[m.Number]
+				break
+			}
+		}
+		if libraries[tx.hash.Hex()] {
+			group = groups[0]
+			deployedLibraries[tx.hash.Hex()] = true
+		}
+
+		for _, g := range []*dryRunGroup{group, total} {
+			g.count++
+			g.gas += receipt.GasUsed
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				g.reverted = append(g.reverted, tx.hash)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Dry run on network %s, forked at block %s of chain %s\n", d.network.Name, d.block, d.chainID)
+	fmt.Printf("Gas price: %s gwei\n", formatUnits(d.gasPrice, big.NewInt(1e9)))
+	if total.count == 0 {
+		fmt.Println("No transactions, every migration has already run")
+		d.warnMissing()
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w)
+	for _, g := range append(groups, total) {
+		if g.count == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%d transactions\t%d gas\t%s ether\n", g.label, g.count, g.gas, d.cost(g.gas))
+		for _, hash := range g.reverted {
+			fmt.Fprintf(w, "  reverted\t%s\t\t\n", hash.Hex())
+		}
+
+		if g == groups[0] {
+			for name, library := range registry.Libraries {
+				if deployedLibraries[library.Transaction] {
+					fmt.Fprintf(w, "  %s\t%s\t\t\n", name, library.Address)
+				}
+			}
+		}
+
+		for _, contract := range deployedIn(registry, g, byNumber, start) {
+			fmt.Fprintf(w, "  %s\t%s\t\t\n", contract, registry.Contracts[contract].Address)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(total.gas), d.gasPrice)
+	if d.deployer != nil && d.balance != nil && d.balance.Cmp(cost) < 0 {
+		fmt.Printf("\nWarning: deployer %s only has %s ether\n", d.deployer.Hex(), weiToEther(d.balance))
+	}
+	d.warnMissing()
+
+	if len(total.reverted) > 0 {
+		return fmt.Errorf("%d transactions reverted", len(total.reverted))
+	}
+
+	return nil
+}
+
+// warnMissing warns of the calls made to addresses with no code on the fork,
+// which may not have done what they would on the network
+func (d *dryRun) warnMissing() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	addresses := make([]common.Address, 0, len(d.missing))
+	for address := range d.missing {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return bytes.Compare(addresses[i][:], addresses[j][:]) < 0 })
+
+	for _, address := range addresses {
+		fmt.Printf("\nWarning: %d calls to %s, which has no code on the fork. Only contracts in the registry are copied from the network, so if it has one there, these calls didn't do what they would on it\n", d.missing[address], address.Hex())
+	}
+}
+
+// deployedIn returns the contracts a group's migration deployed in the dry run
+func deployedIn(registry *deployments.Registry, g *dryRunGroup, byNumber map[int]*dryRunGroup, start time.Time) []string {
+	names := make([]string, 0)
+	for _, name := range sortedContractNames(registry.Contracts) {
+		contract := registry.Contracts[name]
+		if byNumber[contract.Migration] == g && contract.Timestamp.After(start) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// cost is the ether an amount of gas costs at the dry run's gas price
+func (d *dryRun) cost(gas uint64) string {
+	return weiToEther(new(big.Int).Mul(new(big.Int).SetUint64(gas), d.gasPrice))
+}
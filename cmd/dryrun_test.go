@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/zscole/cli/simulated"
+)
+
+func TestStorageRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage map[common.Hash]common.Hash
+		want    common.Hash
+	}{
+		{
+			name:    "empty",
+			storage: map[common.Hash]common.Hash{},
+			want:    types.EmptyRootHash,
+		},
+		{
+			// As geth's state database computes it
+			name:    "slot 0 holding 42",
+			storage: map[common.Hash]common.Hash{common.BigToHash(big.NewInt(0)): common.BigToHash(big.NewInt(42))},
+			want:    common.HexToHash("0x81d1fa699f807735499cf6f7df860797cf66f6a66b565cfcda3fae3521eb6861"),
+		},
+	}
+
+	for _, test := range tests {
+		if got := storageRoot(test.storage); got != test.want {
+			t.Errorf("%s: storageRoot() = %s, want %s", test.name, got.Hex(), test.want.Hex())
+		}
+	}
+}
+
+func TestCheckCode(t *testing.T) {
+	contract := common.HexToAddress("0x00000000000000000000000000000000000c0de0")
+	missing := common.HexToAddress("0x000000000000000000000000000000000000dead")
+
+	chain, err := simulated.New(simulated.Config{
+		Accounts: 1,
+		Alloc:    types.GenesisAlloc{contract: {Code: []byte{0x00}, Balance: new(big.Int)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	d := &dryRun{chain: chain, missing: make(map[common.Address]int)}
+	call := []byte{0x12, 0x34, 0x56, 0x78}
+	sha256 := common.BytesToAddress([]byte{0x02})
+
+	d.checkCode(&contract, call)
+	d.checkCode(&missing, call)
+	d.checkCode(&missing, call)
+	d.checkCode(&missing, nil)
+	d.checkCode(&sha256, call)
+	d.checkCode(nil, call)
+
+	if len(d.missing) != 1 || d.missing[missing] != 2 {
+		t.Errorf("missing = %v, want 2 calls to %s", d.missing, missing.Hex())
+	}
+}
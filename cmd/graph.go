@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var graphFormats = []string{"dot", "json"}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the inheritance, import and library graph of the contracts",
+	Long: `Outputs the inheritance tree of the built contracts, the libraries each one
+uses and the imports between their sources, as a Graphviz DOT graph or JSON.
+Import cycles are reported with the path of sources around them.
+
+Inheritance and libraries are read from the AST saved by ` + "`wb compile`" + `, and
+imports from the sources as they are now.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if !contains(graphFormats, format) {
+			Fatal(fmt.Sprintf("Unknown format %q, must be one of %s", format, strings.Join(graphFormats, ", ")))
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+
+		err := RunInRoot(func() error {
+			graph, err := buildGraph()
+			if err != nil {
+				return err
+			}
+
+			for _, cycle := range graph.Cycles {
+				fmt.Fprintln(os.Stderr, "Warning: import cycle", strings.Join(cycle, " -> "))
+			}
+
+			w := io.Writer(os.Stdout)
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if format == "json" {
+				data, err := json.MarshalIndent(graph, "", "  ")
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprintln(w, string(data))
+				return err
+			}
+
+			return graph.writeDOT(w)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().String("format", "dot", "output format: dot or json")
+	graphCmd.Flags().String("output", "", "file to write the graph to instead of stdout")
+}
+
+// contractGraph is the dependency graph `wb graph` exports
+type contractGraph struct {
+	Contracts []graphContract `json:"contracts"`
+	Sources   []graphSource   `json:"sources"`
+	Cycles    [][]string      `json:"cycles"`
+}
+
+// graphContract is a contract, and the contracts it inherits from in order of
+// declaration and the libraries it uses. Contracts only known as a base of
+// another have no kind or source
+type graphContract struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind,omitempty"`
+	Source    string   `json:"source,omitempty"`
+	Bases     []string `json:"bases"`
+	Libraries []string `json:"libraries"`
+}
+
+// graphSource is a source unit and the units it imports
+type graphSource struct {
+	Name    string   `json:"name"`
+	Library bool     `json:"library,omitempty"`
+	Imports []string `json:"imports"`
+}
+
+// astNode is the part of a solc AST node the graph needs
+type astNode struct {
+	NodeType      string `json:"nodeType"`
+	Name          string `json:"name"`
+	AbsolutePath  string `json:"absolutePath"`
+	ContractKind  string `json:"contractKind"`
+	Abstract      bool   `json:"abstract"`
+	BaseContracts []struct {
+		BaseName struct {
+			Name string `json:"name"`
+		} `json:"baseName"`
+	} `json:"baseContracts"`
+	Nodes []json.RawMessage `json:"nodes"`
+}
+
+// libraryType matches the type of a reference to a library, as in `using
+// Math for uint` or `Math.add(a, b)`
+var libraryType = regexp.MustCompile(`^(?:type\()?library ([A-Za-z_$][A-Za-z0-9_$.]*)\)?$`)
+
+func buildGraph() (*contractGraph, error) {
+	names, err := artifactNames()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("No contracts built, build them with `wb compile`")
+	}
+
+	contracts := make(map[string]*graphContract)
+	for _, name := range names {
+		a, err := loadArtifact(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(a.AST) == 0 {
+			return nil, fmt.Errorf("No AST for %s, rebuild it with `wb compile --force`", name)
+		}
+
+		if err := graphAST(a.AST, contracts); err != nil {
+			return nil, fmt.Errorf("%s: invalid AST: %v", name, err)
+		}
+
+		// Languages without inheritance have no contract definitions
+		c, ok := contracts[name]
+		if !ok {
+			c = &graphContract{Name: name, Kind: "contract", Source: loadBuildManifest().Contracts[name].Source, Bases: []string{}, Libraries: []string{}}
+			contracts[name] = c
+		}
+
+		// Linked libraries are used through calls the AST doesn't type as
+		// library references in every compiler version
+		for _, libs := range a.LinkReferences {
+			for lib := range libs {
+				c.Libraries = appendUnique(c.Libraries, lib)
+			}
+		}
+	}
+
+	// Bases and libraries from outside the project are still nodes
+	for _, c := range contracts {
+		for _, dep := range append(append([]string{}, c.Bases...), c.Libraries...) {
+			if _, ok := contracts[dep]; !ok {
+				contracts[dep] = &graphContract{Name: dep, Bases: []string{}, Libraries: []string{}}
+			}
+		}
+	}
+
+	config, err := loadCompilerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := loadSources(config)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &contractGraph{
+		Contracts: make([]graphContract, 0, len(contracts)),
+		Sources:   make([]graphSource, 0, len(sources)),
+		Cycles:    sources.cycles(),
+	}
+
+	for _, name := range sortedContracts(contracts) {
+		c := contracts[name]
+		sort.Strings(c.Libraries)
+		graph.Contracts = append(graph.Contracts, *c)
+	}
+
+	units := make([]string, 0, len(sources))
+	for unit := range sources {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	for _, unit := range units {
+		graph.Sources = append(graph.Sources, graphSource{
+			Name:    unit,
+			Library: sources[unit].Library,
+			Imports: sources.dependencies(unit),
+		})
+	}
+
+	return graph, nil
+}
+
+// graphAST adds the contracts declared in a source unit's AST to the graph
+func graphAST(data json.RawMessage, contracts map[string]*graphContract) error {
+	var unit astNode
+	if err := json.Unmarshal(data, &unit); err != nil {
+		return err
+	}
+
+	for _, raw := range unit.Nodes {
+		var node astNode
+		if err := json.Unmarshal(raw, &node); err != nil {
+			return err
+		}
+
+		if node.NodeType != "ContractDefinition" {
+			continue
+		}
+
+		// Every contract in a source shares its AST
+		if c, ok := contracts[node.Name]; ok && c.Kind != "" {
+			continue
+		}
+
+		c := &graphContract{
+			Name:      node.Name,
+			Kind:      node.ContractKind,
+			Source:    unit.AbsolutePath,
+			Bases:     make([]string, 0, len(node.BaseContracts)),
+			Libraries: make([]string, 0),
+		}
+		if node.Abstract {
+			c.Kind = "abstract " + c.Kind
+		}
+
+		for _, base := range node.BaseContracts {
+			c.Bases = append(c.Bases, base.BaseName.Name)
+		}
+
+		var body interface{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return err
+		}
+
+		walkAST(body, func(node map[string]interface{}) {
+			types, _ := node["typeDescriptions"].(map[string]interface{})
+			typeString, _ := types["typeString"].(string)
+			if m := libraryType.FindStringSubmatch(typeString); m != nil && m[1] != c.Name {
+				c.Libraries = appendUnique(c.Libraries, m[1])
+			}
+		})
+
+		contracts[c.Name] = c
+	}
+
+	return nil
+}
+
+// walkAST calls visit for every node under an AST node, depth first
+func walkAST(node interface{}, visit func(map[string]interface{})) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		visit(n)
+		for _, child := range n {
+			walkAST(child, visit)
+		}
+	case []interface{}:
+		for _, child := range n {
+			walkAST(child, visit)
+		}
+	}
+}
+
+func appendUnique(list []string, item string) []string {
+	if contains(list, item) {
+		return list
+	}
+
+	return append(list, item)
+}
+
+func sortedContracts(contracts map[string]*graphContract) []string {
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// writeDOT writes the graph for Graphviz. Contracts point to their bases and
+// the libraries they use, and sources to the sources they import, so edges
+// always lead to what a node depends on
+func (g *contractGraph) writeDOT(w io.Writer) error {
+	lines := []string{
+		"digraph contracts {",
+		"  rankdir=BT;",
+		"  node [fontname=\"Helvetica\"];",
+		"",
+		"  subgraph cluster_contracts {",
+		"    label=\"Contracts\";",
+	}
+
+	for _, c := range g.Contracts {
+		shape, style := "box", "solid"
+		switch {
+		case c.Kind == "library":
+			shape = "component"
+		case c.Kind == "interface":
+			style = "dashed"
+		case strings.HasPrefix(c.Kind, "abstract"):
+			style = "rounded"
+		case c.Kind == "":
+			style = "dotted"
+		}
+		lines = append(lines, fmt.Sprintf("    %s [label=%q, shape=%s, style=%s];", dotID("contract", c.Name), c.Name, shape, style))
+	}
+
+	for _, c := range g.Contracts {
+		for _, base := range c.Bases {
+			lines = append(lines, fmt.Sprintf("    %s -> %s [arrowhead=empty];", dotID("contract", c.Name), dotID("contract", base)))
+		}
+		for _, lib := range c.Libraries {
+			lines = append(lines, fmt.Sprintf("    %s -> %s [style=dashed, label=\"uses\"];", dotID("contract", c.Name), dotID("contract", lib)))
+		}
+	}
+
+	lines = append(lines,
+		"  }",
+		"",
+		"  subgraph cluster_sources {",
+		"    label=\"Sources\";",
+	)
+
+	// Edges around a cycle are drawn in red
+	cyclic := make(map[[2]string]bool)
+	for _, cycle := range g.Cycles {
+		for i := 0; i+1 < len(cycle); i++ {
+			cyclic[[2]string{cycle[i], cycle[i+1]}] = true
+		}
+	}
+
+	for _, source := range g.Sources {
+		style := "solid"
+		if source.Library {
+			style = "dotted"
+		}
+		lines = append(lines, fmt.Sprintf("    %s [label=%q, shape=note, style=%s];", dotID("source", source.Name), source.Name, style))
+	}
+
+	for _, source := range g.Sources {
+		for _, imp := range source.Imports {
+			attrs := ""
+			if cyclic[[2]string{source.Name, imp}] {
+				attrs = " [color=red]"
+			}
+			lines = append(lines, fmt.Sprintf("    %s -> %s%s;", dotID("source", source.Name), dotID("source", imp), attrs))
+		}
+	}
+
+	lines = append(lines, "  }", "}")
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// dotID is the quoted id of a node. Ids are prefixed with the kind of node so
+// a contract and a source of the same name are different nodes
+func dotID(kind, name string) string {
+	return fmt.Sprintf("%q", kind+":"+name)
+}
@@ -5,13 +5,20 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 
 	"github.com/zscole/cli/project"
 )
 
 func Fatal(v ...interface{}) {
-	fmt.Println("Error:", v)
+	FatalTo(os.Stdout, v...)
+}
+
+// FatalTo is Fatal printing to w, such as stderr for commands whose stdout is
+// a machine readable document
+func FatalTo(w io.Writer, v ...interface{}) {
+	fmt.Fprintln(w, "Error:", v)
 	os.Exit(1)
 }
 
@@ -44,6 +51,30 @@ func isEmpty(path string) bool {
 	return true
 }
 
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
 func ExecWithOutput(command string, args ...string) error {
 	cmd := exec.Command(command, args...)
 	cmd.Stdout = os.Stdout
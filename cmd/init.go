@@ -20,10 +20,13 @@ and the appropriate structure for a wb-based project.
   * If a name is provided, it will be created in the current directory;
   * If no name is provided, the current directory will be assumed;
   * If a relative path is provided, it will be created inside $GOPATH
+    when run from within it, or in the current directory otherwise
     (e.g. github.com/zscole/cli/);
   * If an absolute path is provided, it will be created;
   * If the directory already exists but is empty, it will be used.
 
+The project is a Go module, named by --module or after the path given.
+
 Init will not use an existing directory with contents.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -49,6 +52,14 @@ Init will not use an existing directory with contents.`,
 			Fatal("please provide only one argument")
 		}
 
+		if prj == nil {
+			Fatal("Invalid project path")
+		}
+
+		if module, _ := cmd.Flags().GetString("module"); module != "" {
+			prj.SetModulePath(module)
+		}
+
 		initializeProject(prj)
 	},
 }
@@ -58,6 +69,7 @@ func init() {
 
 	initCmd.PersistentFlags().StringP("author", "a", "YOUR NAME", "author name for copyright attribution")
 	initCmd.PersistentFlags().StringP("license", "l", "", "name of license for the project")
+	initCmd.PersistentFlags().StringP("module", "m", "", "module path for the project's go.mod")
 
 	viper.BindPFlag("author", initCmd.PersistentFlags().Lookup("author"))
 	viper.SetDefault("author", "NAME HERE <EMAIL ADDRESS>")
@@ -74,9 +86,13 @@ func initializeProject(prj *project.Project) {
 		Fatal("Cowardly refusing to initialize a non-empty dir")
 	}
 
-	if err := templates.RestoreTemplates(prj.AbsPath(), "project", "project", prj.TemplateData()); err != nil {
+	data := prj.TemplateData()
+	data["requires"] = project.RequiredModules()
+
+	if err := templates.RestoreTemplates(prj.AbsPath(), "project", "project", data); err != nil {
 		Fatal(err)
 	}
 
 	fmt.Println("Project initialized in", prj.AbsPath())
+	fmt.Println("Run `go mod tidy` there to fetch its Go dependencies")
 }
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/deployments"
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link",
+	Short: "Link library addresses into contract bytecode for a network",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("default_network", cmd.Flags().Lookup("network"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunInRoot(func() error {
+			return linkContracts(viper.GetString("default_network"), false)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(linkCmd)
+
+	linkCmd.Flags().StringP("network", "n", "dev", "network to link library addresses for")
+}
+
+// linkReference is the location of a library address placeholder in bytecode
+type linkReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// linkReferences maps source files to the libraries they define, and those to
+// every place their address is needed, as saved in a .link artifact
+type linkReferences map[string]map[string][]linkReference
+
+// linker substitutes library addresses into bytecode, deploying any library
+// without a known address if allowed to
+type linker struct {
+	network  *networkConfig
+	registry *deployments.Registry
+	deploy   bool
+
+	ctx    context.Context
+	client *ethclient.Client
+	auth   *bind.TransactOpts
+}
+
+// linkContracts writes the creation and deployed bytecode of every contract
+// that uses a library, linked for a network. Bindings keep the unlinked
+// bytecode, since they're shared by every network, and migrations deploy the
+// linked bytecode of the network they run on
+func linkContracts(network string, deploy bool) error {
+	refs, err := loadLinkReferences()
+	if err != nil {
+		return err
+	}
+
+	// Migrations deploy linked bytecode whenever it exists, so none is kept
+	// for contracts that no longer use a library
+	if err := removeUnlinked(refs); err != nil {
+		return err
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	config, err := loadNetworkConfig(network)
+	if err != nil {
+		return err
+	}
+
+	registry, err := deployments.Load(".", network)
+	if err != nil {
+		return err
+	}
+
+	l := &linker{network: config, registry: registry, deploy: deploy, ctx: context.Background()}
+	defer l.close()
+
+	dir := filepath.Join(deployments.LinkedDirectory, network)
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	for _, name := range sortedLinkNames(refs) {
+		binfile := deployments.LinkedPath(".", network, name)
+		removeFiles(binfile, binfile+"-runtime")
+
+		bin, deployed, err := l.link(name)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(binfile, []byte(bin), 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(binfile+"-runtime", []byte(deployed), 0644); err != nil {
+			return err
+		}
+
+		fmt.Println("Linked", name, "for network", network)
+	}
+
+	return nil
+}
+
+// linkedFiles returns a contract's bytecode linked for every network, creation
+// and deployed
+func linkedFiles(name string) []string {
+	files, _ := filepath.Glob(deployments.LinkedPath(".", "*", name) + "*")
+	return files
+}
+
+// removeUnlinked removes the linked bytecode of every contract without link
+// references, on every network
+func removeUnlinked(refs map[string]linkReferences) error {
+	linked, err := filepath.Glob(deployments.LinkedPath(".", "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range linked {
+		if _, ok := refs[strings.TrimSuffix(filepath.Base(path), ".bin")]; !ok {
+			removeFiles(path, path+"-runtime")
+		}
+	}
+
+	return nil
+}
+
+// loadLinkReferences reads the link references of every contract that needs
+// at least one library linked
+func loadLinkReferences() (map[string]linkReferences, error) {
+	names, err := artifactNames()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]linkReferences)
+	for _, name := range names {
+		a, err := loadArtifact(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(a.LinkReferences) > 0 || len(a.DeployedLinkReferences) > 0 {
+			all[name] = a.LinkReferences
+		}
+	}
+
+	return all, nil
+}
+
+// readLinkFile reads the .link file of a split artifact. Builds from before it
+// held the deployed code's references saved only the creation code's
+func readLinkFile(path string) (linkFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return linkFile{}, err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return linkFile{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var links linkFile
+	_, creation := keys["linkReferences"]
+	_, deployed := keys["deployedLinkReferences"]
+	if creation || deployed {
+		err = json.Unmarshal(data, &links)
+	} else {
+		err = json.Unmarshal(data, &links.LinkReferences)
+	}
+	if err != nil {
+		return linkFile{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return links, nil
+}
+
+// link returns the creation and deployed bytecode of a contract with every
+// library placeholder replaced by the library's address
+func (l *linker) link(name string) (string, string, error) {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return l.linkArtifact(a)
+}
+
+func (l *linker) linkArtifact(a *artifact) (string, string, error) {
+	bin, err := l.substitute(a.ContractName, a.Bytecode, a.LinkReferences)
+	if err != nil {
+		return "", "", err
+	}
+
+	deployed, err := l.substitute(a.ContractName, a.DeployedBytecode, a.DeployedLinkReferences)
+	if err != nil {
+		return "", "", err
+	}
+
+	return bin, deployed, nil
+}
+
+// substitute replaces the placeholder at every link reference in bytecode with
+// the address of its library
+func (l *linker) substitute(name, bytecode string, refs linkReferences) (string, error) {
+	bin := []byte(bytecode)
+	for _, file := range sortedLinkFiles(refs) {
+		for _, library := range sortedLinkLibraries(refs[file]) {
+			address, err := l.address(file, library)
+			if err != nil {
+				return "", err
+			}
+
+			for _, ref := range refs[file][library] {
+				start, end := ref.Start*2, (ref.Start+ref.Length)*2
+				if end > len(bin) || !strings.HasPrefix(string(bin[start:end]), "__") {
+					return "", fmt.Errorf("%s: no placeholder for library %s at offset %d", name, library, ref.Start)
+				}
+
+				copy(bin[start:end], address)
+			}
+		}
+	}
+
+	return string(bin), nil
+}
+
+// address returns the address of a library as hex without a 0x prefix,
+// looking in the network config, then the deployment registry, and finally
+// deploying the library if allowed to
+func (l *linker) address(file, library string) (string, error) {
+	qualified := file + ":" + library
+
+	// viper lowercases map keys, so configured names are matched regardless
+	// of case
+	address, ok := l.network.Libraries[strings.ToLower(qualified)]
+	if !ok {
+		address, ok = l.network.Libraries[strings.ToLower(library)]
+	}
+	if !ok {
+		if deployed, found := l.registry.Libraries[qualified]; found {
+			address, ok = deployed.Address, true
+		}
+	}
+
+	if !ok {
+		if !l.deploy {
+			return "", fmt.Errorf("No address for library %s on network %s, set networks.%s.libraries in wb.yaml or deploy it with `wb migrate`", library, l.network.Name, l.network.Name)
+		}
+
+		deployed, err := l.deployLibrary(file, library)
+		if err != nil {
+			return "", err
+		}
+		address = deployed
+	}
+
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("Invalid address %q for library %s", address, library)
+	}
+
+	return strings.ToLower(strings.TrimPrefix(common.HexToAddress(address).Hex(), "0x")), nil
+}
+
+func (l *linker) deployLibrary(file, library string) (string, error) {
+	a, err := libraryArtifact(file, library)
+	if err != nil {
+		return "", err
+	}
+
+	// Libraries may themselves need other libraries linked
+	bin, _, err := l.linkArtifact(a)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(string(a.ABI)))
+	if err != nil {
+		return "", err
+	}
+
+	if l.client == nil {
+		l.client, l.auth, err = l.network.transactor(l.ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Println("Deploying library", library, "to network", l.network.Name)
+	address, tx, _, err := bind.DeployContract(l.auth, parsed, common.FromHex(bin), l.client)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := bind.WaitDeployed(l.ctx, l.client, tx); err != nil {
+		return "", err
+	}
+
+	l.registry.Libraries[file+":"+library] = deployments.Library{
+		Address:     address.Hex(),
+		Transaction: tx.Hash().Hex(),
+	}
+	if err := l.registry.Save(); err != nil {
+		return "", err
+	}
+
+	return address.Hex(), nil
+}
+
+// libraryArtifact loads the artifact of a library defined in a file.
+// Artifacts are saved by contract name, so one built from another file of
+// the same name is refused rather than deployed in its place
+func libraryArtifact(file, library string) (*artifact, error) {
+	a, err := loadArtifact(library)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split artifacts don't record their source, the build manifest does
+	source := a.SourceName
+	if source == "" {
+		source = loadBuildManifest().Contracts[library].Source
+	}
+
+	if source != "" && source != file {
+		return nil, fmt.Errorf("No artifact for library %s:%s, the %s in the build directory is from %s", file, library, library, source)
+	}
+
+	return a, nil
+}
+
+func (l *linker) close() {
+	if l.client != nil {
+		l.client.Close()
+	}
+}
+
+func sortedLinkNames(m map[string]linkReferences) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedLinkFiles(refs linkReferences) []string {
+	files := make([]string, 0, len(refs))
+	for file := range refs {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+func sortedLinkLibraries(libraries map[string][]linkReference) []string {
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
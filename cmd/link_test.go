@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zscole/cli/deployments"
+	"github.com/zscole/cli/project"
+)
+
+func TestLinkArtifact(t *testing.T) {
+	placeholder := "__$" + strings.Repeat("a", 34) + "$__"
+	math := strings.Repeat("1", 38) + "aa"
+	owned := strings.Repeat("2", 38) + "bb"
+
+	l := &linker{
+		network: &networkConfig{
+			Name: "dev",
+			Libraries: map[string]string{
+				"lib/math.sol:math": "0x" + strings.ToUpper(math),
+			},
+		},
+		registry: &deployments.Registry{
+			Libraries: map[string]deployments.Library{
+				"lib/Owned.sol:Owned": {Address: "0x" + owned},
+			},
+		},
+	}
+
+	refs := func(file, library string, starts ...int) linkReferences {
+		r := make([]linkReference, 0, len(starts))
+		for _, start := range starts {
+			r = append(r, linkReference{Start: start, Length: 20})
+		}
+		return linkReferences{file: {library: r}}
+	}
+
+	tests := []struct {
+		name            string
+		artifact        artifact
+		bin, deployed   string
+		err             string
+		librariesByName map[string]string
+	}{
+		{
+			name: "configured library",
+			artifact: artifact{
+				Bytecode:               "6080" + placeholder + "00" + placeholder,
+				DeployedBytecode:       "60" + placeholder,
+				LinkReferences:         refs("lib/Math.sol", "Math", 2, 23),
+				DeployedLinkReferences: refs("lib/Math.sol", "Math", 1),
+			},
+			bin:      "6080" + math + "00" + math,
+			deployed: "60" + math,
+		},
+		{
+			name: "deployed library",
+			artifact: artifact{
+				Bytecode:               placeholder,
+				DeployedBytecode:       "00" + placeholder,
+				LinkReferences:         refs("lib/Owned.sol", "Owned", 0),
+				DeployedLinkReferences: refs("lib/Owned.sol", "Owned", 1),
+			},
+			bin:      owned,
+			deployed: "00" + owned,
+		},
+		{
+			name: "library configured by name",
+			artifact: artifact{
+				Bytecode:       placeholder,
+				LinkReferences: refs("other/Math.sol", "Math", 0),
+			},
+			librariesByName: map[string]string{"math": "0x" + math},
+			bin:             math,
+		},
+		{
+			name: "unlinked",
+			artifact: artifact{
+				Bytecode:         "6080",
+				DeployedBytecode: "60",
+			},
+			bin:      "6080",
+			deployed: "60",
+		},
+		{
+			name: "no placeholder",
+			artifact: artifact{
+				Bytecode:       "6080" + strings.Repeat("0", 40),
+				LinkReferences: refs("lib/Math.sol", "Math", 2),
+			},
+			err: "no placeholder for library Math at offset 2",
+		},
+		{
+			name: "reference past the end",
+			artifact: artifact{
+				Bytecode:       placeholder,
+				LinkReferences: refs("lib/Math.sol", "Math", 1),
+			},
+			err: "no placeholder for library Math at offset 1",
+		},
+		{
+			name: "unknown library",
+			artifact: artifact{
+				Bytecode:       placeholder,
+				LinkReferences: refs("lib/Strings.sol", "Strings", 0),
+			},
+			err: "No address for library Strings on network dev",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, address := range test.librariesByName {
+				l.network.Libraries[name] = address
+				defer delete(l.network.Libraries, name)
+			}
+
+			test.artifact.ContractName = "Token"
+			bin, deployed, err := l.linkArtifact(&test.artifact)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("linkArtifact() error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bin != test.bin {
+				t.Errorf("bytecode = %s, want %s", bin, test.bin)
+			}
+			if deployed != test.deployed {
+				t.Errorf("deployed bytecode = %s, want %s", deployed, test.deployed)
+			}
+		})
+	}
+}
+
+func TestReadLinkFile(t *testing.T) {
+	creation := linkReferences{"lib/Math.sol": {"Math": {{Start: 2, Length: 20}}}}
+	deployed := linkReferences{"lib/Math.sol": {"Math": {{Start: 1, Length: 20}}}}
+
+	tests := []struct {
+		name string
+		data string
+		want linkFile
+	}{
+		{
+			name: "creation and deployed",
+			data: `{"linkReferences":{"lib/Math.sol":{"Math":[{"start":2,"length":20}]}},"deployedLinkReferences":{"lib/Math.sol":{"Math":[{"start":1,"length":20}]}}}`,
+			want: linkFile{LinkReferences: creation, DeployedLinkReferences: deployed},
+		},
+		{
+			name: "none",
+			data: `{"linkReferences":{},"deployedLinkReferences":{}}`,
+			want: linkFile{LinkReferences: linkReferences{}, DeployedLinkReferences: linkReferences{}},
+		},
+		{
+			name: "creation only, from older builds",
+			data: `{"lib/Math.sol":{"Math":[{"start":2,"length":20}]}}`,
+			want: linkFile{LinkReferences: creation},
+		},
+		{
+			name: "none, from older builds",
+			data: `null`,
+		},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "Token.link")
+			if err := ioutil.WriteFile(path, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readLinkFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("readLinkFile() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveStaleLinkedBytecode(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	write := func(network, name string) {
+		path := deployments.LinkedPath(".", network, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range []string{path, path + "-runtime"} {
+			if err := ioutil.WriteFile(file, []byte("6080"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	remaining := func() []string {
+		files, err := filepath.Glob(deployments.LinkedPath(".", "*", "*") + "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	write("dev", "Token")
+	write("dev", "Vault")
+	write("live", "Token")
+	write("live", "TokenSale")
+
+	// Vault and TokenSale no longer use a library
+	refs := map[string]linkReferences{"Token": {"lib/Math.sol": {"Math": {{Start: 2, Length: 20}}}}}
+	if err := removeUnlinked(refs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(deployments.LinkedDirectory, "dev", "Token.bin"),
+		filepath.Join(deployments.LinkedDirectory, "dev", "Token.bin-runtime"),
+		filepath.Join(deployments.LinkedDirectory, "live", "Token.bin"),
+		filepath.Join(deployments.LinkedDirectory, "live", "Token.bin-runtime"),
+	}
+	if got := remaining(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after removeUnlinked, linked files = %q, want %q", got, want)
+	}
+
+	// Recompiling Token makes its linked bytecode stale on every network
+	if err := os.MkdirAll(project.BuildDirectory, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeArtifact(&artifact{ContractName: "Token", ABI: []byte("[]")}, &artifactsConfig{Format: splitArtifacts}); err != nil {
+		t.Fatal(err)
+	}
+	if got := remaining(); len(got) != 0 {
+		t.Errorf("after writeArtifact, linked files = %q, want none", got)
+	}
+}
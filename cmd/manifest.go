@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zscole/cli/project"
+)
+
+const manifestFilename = ".manifest.json"
+
+// buildManifest records every file compiling has produced, and the contract
+// each belongs to, so outputs of contracts that no longer exist can be removed
+// without touching anything else
+type buildManifest struct {
+	Contracts map[string]manifestEntry `json:"contracts"`
+
+	// Bindings maps contracts to the binding generated for them
+	Bindings map[string]string `json:"bindings"`
+
+	exists bool
+}
+
+// manifestEntry is the source a contract was built from and its artifacts
+type manifestEntry struct {
+	Source string   `json:"source"`
+	Files  []string `json:"files"`
+}
+
+func manifestPath() string {
+	return filepath.Join(project.BuildDirectory, manifestFilename)
+}
+
+func loadBuildManifest() *buildManifest {
+	manifest := &buildManifest{}
+
+	data, err := ioutil.ReadFile(manifestPath())
+	if err == nil {
+		manifest.exists = json.Unmarshal(data, manifest) == nil
+	}
+
+	if manifest.Contracts == nil {
+		manifest.Contracts = make(map[string]manifestEntry)
+	}
+	if manifest.Bindings == nil {
+		manifest.Bindings = make(map[string]string)
+	}
+
+	return manifest
+}
+
+func (m *buildManifest) save() error {
+	if err := os.MkdirAll(project.BuildDirectory, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(manifestPath(), data, 0644)
+}
+
+// adopt takes ownership of the outputs of every contract in the build
+// directory, for builds from before the manifest was kept. They aren't tied to
+// a source, so any not rebuilt are removed as orphans
+func (m *buildManifest) adopt() error {
+	config, err := loadBindingsConfig()
+	if err != nil {
+		return err
+	}
+
+	names, err := artifactNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		path := filepath.Join(project.BuildDirectory, name)
+		files := append(artifactFiles(path, splitArtifactExtensions...), path+combinedArtifactExtension)
+		m.Contracts[name] = manifestEntry{Files: files}
+		m.Bindings[name] = config.path(name)
+	}
+
+	return nil
+}
+
+// record adds the artifacts saved by a build
+func (m *buildManifest) record(artifacts []*artifact) {
+	for _, a := range artifacts {
+		m.Contracts[a.ContractName] = manifestEntry{Source: a.SourceName, Files: a.files}
+	}
+}
+
+// orphans returns the contracts whose source is gone, or was rebuilt without
+// producing them
+func (m *buildManifest) orphans(sources sourceSet, rebuilt []string, artifacts []*artifact) []string {
+	built := make(map[string]bool)
+	for _, a := range artifacts {
+		built[a.ContractName] = true
+	}
+
+	orphans := make(map[string]bool)
+	for name, entry := range m.Contracts {
+		if _, ok := sources[entry.Source]; !ok {
+			orphans[name] = true
+		} else if contains(rebuilt, entry.Source) && !built[name] {
+			orphans[name] = true
+		}
+	}
+
+	return sortedKeys(orphans)
+}
+
+// remove deletes everything produced for a contract
+func (m *buildManifest) remove(name string) {
+	paths := append([]string{}, m.Contracts[name].Files...)
+	if binding, ok := m.Bindings[name]; ok {
+		paths = append(paths, binding)
+	}
+
+	paths = append(paths, linkedFiles(name)...)
+
+	removeFiles(paths...)
+
+	delete(m.Contracts, name)
+	delete(m.Bindings, name)
+}
+
+// pruneOutputs removes the outputs of contracts that no longer exist after a
+// successful build, along with their cached state
+func pruneOutputs(w io.Writer, sources sourceSet, rebuilt []string, artifacts []*artifact, cache *buildCache) error {
+	manifest := loadBuildManifest()
+	if !manifest.exists {
+		if err := manifest.adopt(); err != nil {
+			return err
+		}
+	}
+	manifest.record(artifacts)
+
+	orphans := manifest.orphans(sources, rebuilt, artifacts)
+	if len(orphans) > 0 {
+		sizes := loadSizeRecord()
+		for _, name := range orphans {
+			manifest.remove(name)
+			delete(cache.Bindings, name)
+			delete(sizes.Current, name)
+			delete(sizes.Previous, name)
+
+			fmt.Fprintln(w, "Removed outputs of", name)
+		}
+
+		if err := sizes.save(); err != nil {
+			return err
+		}
+	}
+
+	return manifest.save()
+}
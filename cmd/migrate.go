@@ -1,41 +1,118 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/deployments"
 )
 
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Run migrations to deploy contracts",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("default_network", cmd.Flags().Lookup("network"))
+		viper.BindPFlag("reset", cmd.Flags().Lookup("reset"))
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		stub_args := []string{"--network", viper.GetString("default_network")}
-		if viper.GetBool("reset") {
-			stub_args = append(stub_args, "--reset")
+		run := runMigrations
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			run = dryRunMigrations
 		}
 
-		if err := runStub("migrate", stub_args...); err != nil {
+		if err := run(viper.GetString("default_network"), viper.GetBool("reset")); err != nil {
 			Fatal(err)
 		}
 	},
 }
 
 var deployCmd = &cobra.Command{
-	Use:   "deploy",
-	Short: "(alias for migrate)",
-	Run:   migrateCmd.Run,
+	Use:    "deploy",
+	Short:  "(alias for migrate)",
+	PreRun: migrateCmd.PreRun,
+	Run:    migrateCmd.Run,
 }
 
 func init() {
 	RootCmd.AddCommand(migrateCmd)
 	RootCmd.AddCommand(deployCmd)
 
-	migrateCmd.PersistentFlags().StringP("network", "n", "NAME", "network to run migrations on")
-	migrateCmd.PersistentFlags().Bool("reset", false, "redeploy all migrations")
-	deployCmd.PersistentFlags().StringP("network", "n", "NAME", "network to run migrations on")
-	deployCmd.PersistentFlags().Bool("reset", false, "redeploy all migrations")
+	// Both commands bind their own flags when they run, since viper keys
+	// can only be bound to one flag
+	for _, cmd := range []*cobra.Command{migrateCmd, deployCmd} {
+		cmd.Flags().StringP("network", "n", "dev", "network to run migrations on")
+		cmd.Flags().Bool("reset", false, "redeploy all migrations")
+		cmd.Flags().Bool("dry-run", false, fmt.Sprintf("run the migrations on a fork of the network and report their gas, without sending anything. "+
+			"The fork only has the network's keystore accounts, libraries and registry contracts, and can't copy a contract with storage past its first %d slots. "+
+			"Other contracts have no code on it", dryRunSlots))
+	}
 
-	viper.BindPFlag("reset", migrateCmd.PersistentFlags().Lookup("reset"))
-	viper.BindPFlag("default_network", migrateCmd.PersistentFlags().Lookup("network"))
 	viper.SetDefault("default_network", "dev")
 }
+
+func resetDeployments(network string) error {
+	registry, err := deployments.Load(".", network)
+	if err != nil {
+		return err
+	}
+
+	registry.Reset()
+	return registry.Save()
+}
+
+// runMigrations runs the migrations on a network, or all of them again if
+// reset
+func runMigrations(network string, reset bool) error {
+	// Deploy any libraries the contracts need and link them in first, so
+	// migrations deploy fully linked bytecode
+	err := RunInRoot(func() error {
+		// Migrations run in the stub, which can't reach a chain that
+		// only exists in this process
+		if viper.IsSet("networks." + network) {
+			config, err := loadNetworkConfig(network)
+			if err != nil {
+				return err
+			}
+
+			if config.simulated() {
+				return fmt.Errorf("Network %q is simulated, so only exists while `wb test` runs", network)
+			}
+
+			// Nothing is deployed to a node on the wrong chain
+			if config.ChainID != 0 {
+				if _, _, err := config.check(30 * time.Second); err != nil {
+					return err
+				}
+			}
+
+			if err := config.deployerEnv(); err != nil {
+				return err
+			}
+		}
+
+		return linkContracts(network, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	stub_args := []string{"--network", network}
+	if reset {
+		stub_args = append(stub_args, "--reset")
+
+		// Every migration is about to run again, and record its
+		// contracts afresh
+		if err := RunInRoot(func() error { return resetDeployments(network) }); err != nil {
+			return err
+		}
+	}
+
+	// Migrations record what they deploy in the registry of this network
+	os.Setenv(deployments.NetworkEnv, network)
+
+	return runStub("migrate", stub_args...)
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// natspec is the userdoc and devdoc output of the compiler for a contract
+type natspec struct {
+	Userdoc natspecDoc
+	Devdoc  natspecDoc
+}
+
+// natspecDoc is either a userdoc or a devdoc. Members are keyed by their
+// canonical signature, and errors are lists since several errors can share one
+type natspecDoc struct {
+	Title   string                    `json:"title"`
+	Author  string                    `json:"author"`
+	Notice  string                    `json:"notice"`
+	Details string                    `json:"details"`
+	Methods map[string]natspecEntry   `json:"methods"`
+	Events  map[string]natspecEntry   `json:"events"`
+	Errors  map[string][]natspecEntry `json:"errors"`
+}
+
+// natspecEntry documents a single function, event or error
+type natspecEntry struct {
+	Notice  string            `json:"notice"`
+	Details string            `json:"details"`
+	Params  map[string]string `json:"params"`
+	Returns map[string]string `json:"returns"`
+
+	// Return is the single return value description of compilers before 0.5
+	Return string `json:"return"`
+}
+
+// UnmarshalJSON accepts the plain string older compilers give the constructor
+// notice as
+func (e *natspecEntry) UnmarshalJSON(data []byte) error {
+	var notice string
+	if json.Unmarshal(data, &notice) == nil {
+		e.Notice = notice
+		return nil
+	}
+
+	type entry natspecEntry
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+func parseNatspec(a *artifact) (*natspec, error) {
+	docs := &natspec{}
+	if len(a.Userdoc) > 0 {
+		if err := json.Unmarshal(a.Userdoc, &docs.Userdoc); err != nil {
+			return nil, fmt.Errorf("%s: invalid userdoc: %v", a.ContractName, err)
+		}
+	}
+	if len(a.Devdoc) > 0 {
+		if err := json.Unmarshal(a.Devdoc, &docs.Devdoc); err != nil {
+			return nil, fmt.Errorf("%s: invalid devdoc: %v", a.ContractName, err)
+		}
+	}
+
+	return docs, nil
+}
+
+// method merges the user and developer documentation of a function
+func (n *natspec) method(signature string) natspecEntry {
+	return mergeNatspec(n.Userdoc.Methods[signature], n.Devdoc.Methods[signature])
+}
+
+func (n *natspec) event(signature string) natspecEntry {
+	return mergeNatspec(n.Userdoc.Events[signature], n.Devdoc.Events[signature])
+}
+
+func (n *natspec) customError(signature string) natspecEntry {
+	var user, dev natspecEntry
+	if entries := n.Userdoc.Errors[signature]; len(entries) > 0 {
+		user = entries[0]
+	}
+	if entries := n.Devdoc.Errors[signature]; len(entries) > 0 {
+		dev = entries[0]
+	}
+
+	return mergeNatspec(user, dev)
+}
+
+func mergeNatspec(user, dev natspecEntry) natspecEntry {
+	entry := dev
+	entry.Notice = user.Notice
+	if entry.Returns == nil && dev.Return != "" {
+		entry.Returns = map[string]string{"_0": dev.Return}
+	}
+
+	return entry
+}
+
+// lines returns the text of an entry as paragraphs, for comments
+func (e natspecEntry) lines() []string {
+	lines := make([]string, 0)
+	for _, text := range []string{e.Notice, e.Details} {
+		if text = strings.TrimSpace(text); text != "" {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, strings.Split(text, "\n")...)
+		}
+	}
+
+	return lines
+}
+
+// abiEntry is an entry of a contract's abi
+type abiEntry struct {
+	Type            string        `json:"type"`
+	Name            string        `json:"name"`
+	Inputs          []abiArgument `json:"inputs"`
+	Outputs         []abiArgument `json:"outputs"`
+	StateMutability string        `json:"stateMutability"`
+	Anonymous       bool          `json:"anonymous"`
+
+	// sig and id are the canonical signature and the selector or topic, as
+	// go-ethereum's abi package computes them for `wb selectors`
+	sig string
+	id  []byte
+}
+
+type abiArgument struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Indexed    bool          `json:"indexed"`
+	Components []abiArgument `json:"components"`
+
+	canonical string
+}
+
+func parseABI(data []byte) ([]abiEntry, error) {
+	raw := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	entries := make([]abiEntry, 0, len(raw))
+	for _, fields := range raw {
+		// Functions without a type are from before the abi had types
+		if fields["type"] == nil || fields["type"] == "" {
+			fields["type"] = "function"
+		}
+
+		data, err := json.Marshal([]interface{}{fields})
+		if err != nil {
+			return nil, err
+		}
+
+		var entry []abiEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+
+		if err := entry[0].canonicalize(data); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry[0])
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+// canonicalize sets the signature, selector and argument types of an entry
+// from go-ethereum's reading of it, so docs, bindings and the selectors of
+// proxies agree however tuples are nested
+func (e *abiEntry) canonicalize(data []byte) error {
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	var inputs, outputs abi.Arguments
+	switch e.Type {
+	case "constructor":
+		inputs = parsed.Constructor.Inputs
+	case "function":
+		for _, method := range parsed.Methods {
+			e.sig, e.id, inputs, outputs = method.Sig, method.ID, method.Inputs, method.Outputs
+		}
+	case "event":
+		for _, event := range parsed.Events {
+			e.sig, e.id, inputs = event.Sig, event.ID[:], event.Inputs
+		}
+	case "error":
+		for _, customError := range parsed.Errors {
+			e.sig, e.id, inputs = customError.Sig, customError.ID[:4], customError.Inputs
+		}
+	}
+
+	for i := range e.Inputs {
+		if i < len(inputs) {
+			e.Inputs[i].canonical = inputs[i].Type.String()
+		}
+	}
+	for i := range e.Outputs {
+		if i < len(outputs) {
+			e.Outputs[i].canonical = outputs[i].Type.String()
+		}
+	}
+
+	return nil
+}
+
+// canonicalType is a type as written in signatures, with tuples expanded to
+// the types of their components
+func (a abiArgument) canonicalType() string {
+	return a.canonical
+}
+
+// signature is the canonical signature that selectors and topics hash, and
+// NatSpec output is keyed by
+func (e abiEntry) signature() string {
+	return e.sig
+}
+
+// selector is the function or error selector, or the event topic
+func (e abiEntry) selector() string {
+	return hexutil.Encode(e.id)
+}
+
+// natspecModifier is a modifier and its NatSpec comment, which the compiler
+// doesn't output and so are read from the source
+type natspecModifier struct {
+	Name      string
+	Signature string
+	Entry     natspecEntry
+}
+
+var (
+	modifierPattern = regexp.MustCompile(`\bmodifier\s+([A-Za-z_$][A-Za-z0-9_$]*)\s*(\([^)]*\))?`)
+	natspecTag      = regexp.MustCompile(`^@(\w+)\s*(.*)$`)
+)
+
+// sourceModifiers returns the modifiers declared by a contract in a source
+func sourceModifiers(content []byte, contract string) []natspecModifier {
+	// Comments are blanked out without moving anything, so offsets into the
+	// stripped code are offsets into the source
+	code, stripped := string(content), stripComments(string(content))
+	body := contractBody(stripped, contract)
+	if body[1] == 0 {
+		return nil
+	}
+
+	modifiers := make([]natspecModifier, 0)
+	for _, loc := range modifierPattern.FindAllStringSubmatchIndex(stripped[body[0]:body[1]], -1) {
+		start := body[0] + loc[0]
+		signature := code[body[0]+loc[2] : body[0]+loc[3]]
+		if loc[4] >= 0 {
+			signature += strings.Join(strings.Fields(code[body[0]+loc[4]:body[0]+loc[5]]), " ")
+		}
+
+		modifiers = append(modifiers, natspecModifier{
+			Name:      code[body[0]+loc[2] : body[0]+loc[3]],
+			Signature: signature,
+			Entry:     parseNatspecComment(precedingComment(code, start)),
+		})
+	}
+
+	sort.SliceStable(modifiers, func(i, j int) bool {
+		return modifiers[i].Name < modifiers[j].Name
+	})
+
+	return modifiers
+}
+
+// contractBody returns the offsets of the braces around a contract's body, in
+// code with its comments stripped
+func contractBody(code, contract string) [2]int {
+	pattern := regexp.MustCompile(`\b(?:contract|library|interface)\s+` + regexp.QuoteMeta(contract) + `\b[^{]*\{`)
+	loc := pattern.FindStringIndex(code)
+	if loc == nil {
+		return [2]int{}
+	}
+
+	depth := 0
+	for i := loc[1] - 1; i < len(code); i++ {
+		switch code[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return [2]int{loc[1], i}
+			}
+		}
+	}
+
+	return [2]int{}
+}
+
+// precedingComment returns the NatSpec comment, /// lines or a /** block,
+// immediately before an offset
+func precedingComment(code string, offset int) string {
+	lines := strings.Split(code[:offset], "\n")
+
+	// The declaration may be indented on its own line
+	if strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	comment := make([]string, 0)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "///") {
+			comment = append([]string{strings.TrimSpace(strings.TrimPrefix(line, "///"))}, comment...)
+			continue
+		}
+
+		if len(comment) == 0 && strings.HasSuffix(line, "*/") {
+			for j := i; j >= 0; j-- {
+				text := strings.TrimSpace(lines[j])
+				if strings.HasPrefix(text, "/**") {
+					block := make([]string, 0)
+					for _, l := range lines[j : i+1] {
+						l = strings.TrimSpace(l)
+						l = strings.TrimPrefix(l, "/**")
+						l = strings.TrimSuffix(l, "*/")
+						l = strings.TrimPrefix(strings.TrimSpace(l), "*")
+						block = append(block, strings.TrimSpace(l))
+					}
+					return strings.TrimSpace(strings.Join(block, "\n"))
+				}
+			}
+		}
+
+		break
+	}
+
+	return strings.Join(comment, "\n")
+}
+
+// parseNatspecComment reads the tags of a NatSpec comment. Untagged text is a
+// notice
+func parseNatspecComment(comment string) natspecEntry {
+	entry := natspecEntry{Params: make(map[string]string)}
+
+	tag, param := "notice", ""
+	for _, line := range strings.Split(comment, "\n") {
+		if m := natspecTag.FindStringSubmatch(line); m != nil {
+			tag, line = m[1], m[2]
+			if tag == "param" {
+				fields := strings.SplitN(line, " ", 2)
+				param, line = fields[0], ""
+				if len(fields) == 2 {
+					line = fields[1]
+				}
+			}
+		}
+
+		add := func(s *string) {
+			if *s != "" && line != "" {
+				*s += " "
+			}
+			*s += line
+		}
+
+		switch tag {
+		case "notice":
+			add(&entry.Notice)
+		case "dev":
+			add(&entry.Details)
+		case "param":
+			text := entry.Params[param]
+			add(&text)
+			entry.Params[param] = text
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseABI(t *testing.T) {
+	data := `[
+		{"type":"constructor","inputs":[{"name":"owner","type":"address"}],"stateMutability":"nonpayable"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+		{"name":"legacy","inputs":[],"outputs":[],"constant":true},
+		{"type":"function","name":"settle","inputs":[
+			{"name":"order","type":"tuple","components":[
+				{"name":"amount","type":"uint256"},
+				{"name":"legs","type":"tuple[]","components":[{"name":"to","type":"address"},{"name":"data","type":"bytes32"}]}
+			]},
+			{"name":"kind","type":"uint8"}
+		],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false},
+		{"type":"error","name":"Insufficient","inputs":[{"name":"needed","type":"uint256"}]}
+	]`
+
+	entries, err := parseABI([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		kind, signature, selector string
+	}{
+		{"constructor", "", "0x"},
+		{"error", "Insufficient(uint256)", ""},
+		{"event", "Transfer(address,address,uint256)", "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+		{"function", "legacy()", ""},
+		{"function", "settle((uint256,(address,bytes32)[]),uint8)", ""},
+		{"function", "transfer(address,uint256)", "0xa9059cbb"},
+		{"function", "transfer(address)", "0x1a695230"},
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("parsed %d entries, want %d", len(entries), len(tests))
+	}
+
+	for i, test := range tests {
+		entry := entries[i]
+		if entry.Type != test.kind || entry.signature() != test.signature {
+			t.Errorf("entry %d = %s %s, want %s %s", i, entry.Type, entry.signature(), test.kind, test.signature)
+		}
+
+		want := test.selector
+		if want == "" {
+			hash := crypto.Keccak256([]byte(test.signature))
+			want = hexutil.Encode(hash[:4])
+		}
+		if entry.selector() != want {
+			t.Errorf("%s: selector = %s, want %s", test.signature, entry.selector(), want)
+		}
+	}
+
+	settle := entries[4]
+	if got := settle.Inputs[0].canonicalType(); got != "(uint256,(address,bytes32)[])" {
+		t.Errorf("settle's order type = %s, want (uint256,(address,bytes32)[])", got)
+	}
+	if got := entries[5].Outputs[0].canonicalType(); got != "bool" {
+		t.Errorf("transfer's output type = %s, want bool", got)
+	}
+}
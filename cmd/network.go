@@ -0,0 +1,604 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zscole/cli/deployer"
+	"github.com/zscole/cli/simulated"
+)
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Manage the networks in wb.yaml",
+}
+
+var networkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured networks",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := RunInRoot(listNetworks); err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+var networkAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a network to wb.yaml",
+	Long: `Adds a network to the networks section of wb.yaml, or replaces it with
+--force. Comments in wb.yaml are kept, though it's reindented.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := networkFromFlags(cmd, args[0])
+		if err != nil {
+			Fatal(err)
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if err := RunInRoot(func() error { return addNetwork(config, force) }); err != nil {
+			Fatal(err)
+		}
+
+		fmt.Println("Added network", config.Name)
+	},
+}
+
+var networkRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a network from wb.yaml",
+	Long: `Removes a network from the networks section of wb.yaml. Its keystore and the
+contracts recorded as deployed to it are left alone.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := RunInRoot(func() error { return removeNetwork(args[0]) }); err != nil {
+			Fatal(err)
+		}
+
+		fmt.Println("Removed network", args[0])
+	},
+}
+
+var networkCheckCmd = &cobra.Command{
+	Use:   "check [NAME...]",
+	Short: "Connect to networks and check they're on the configured chain",
+	Long: `Connects to each network given, or every network with a url, and reports the
+chain it's on and its latest block. A network whose chain id doesn't match
+its chain_id in wb.yaml, or that can't be reached, fails the check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if err := RunInRoot(func() error { return checkNetworks(args, timeout) }); err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(networkCmd)
+	networkCmd.AddCommand(networkListCmd)
+	networkCmd.AddCommand(networkAddCmd)
+	networkCmd.AddCommand(networkRemoveCmd)
+	networkCmd.AddCommand(networkCheckCmd)
+
+	networkAddCmd.Flags().String("type", rpcNetwork, "type of network: rpc or simulated")
+	networkAddCmd.Flags().String("url", "", "url of the node, http, websocket or an IPC path")
+	networkAddCmd.Flags().String("keystore", "", "keystore directory of the accounts to send transactions from")
+	networkAddCmd.Flags().Int64("chain-id", 0, "chain id the node must be on")
+	networkAddCmd.Flags().String("gas-price", "", "gas price in gwei, instead of the node's suggestion")
+	networkAddCmd.Flags().Uint64("gas-limit", 0, "gas limit of transactions, instead of estimating it")
+	networkAddCmd.Flags().String("deployer", "", "address or keystore index of the account to deploy from")
+	networkAddCmd.Flags().String("password-file", "", "file holding the deployer's passphrase, instead of prompting")
+	networkAddCmd.Flags().Int("accounts", 0, "number of funded accounts of a simulated network")
+	networkAddCmd.Flags().String("balance", "", "ether each account of a simulated network is funded with")
+	networkAddCmd.Flags().Bool("force", false, "replace the network if it exists")
+
+	networkCheckCmd.Flags().Duration("timeout", 10*time.Second, "time to wait for each network")
+}
+
+const (
+	// rpcNetwork is a node reached at the network's url
+	rpcNetwork = "rpc"
+
+	// simulatedNetwork is a chain that only exists within the process using
+	// it, started afresh each time
+	simulatedNetwork = "simulated"
+)
+
+var networkTypes = []string{rpcNetwork, simulatedNetwork}
+
+// networkConfig is an entry of the `networks:` section of wb.yaml
+type networkConfig struct {
+	Name     string
+	Type     string `mapstructure:"type"`
+	URL      string `mapstructure:"url"`
+	Keystore string `mapstructure:"keystore"`
+
+	// ChainID is the chain the url must be on, checked before anything is
+	// sent to it
+	ChainID int64 `mapstructure:"chain_id"`
+
+	// GasPrice, in gwei, and GasLimit replace the estimates of the node for
+	// transactions wb sends
+	GasPrice string `mapstructure:"gas_price"`
+	GasLimit uint64 `mapstructure:"gas_limit"`
+
+	// Deployer is the address of the keystore account to send transactions
+	// from, or its index, the first if unset. PasswordFile holds its
+	// passphrase, which is prompted for if there's none
+	Deployer     string `mapstructure:"deployer"`
+	PasswordFile string `mapstructure:"password_file"`
+
+	// Accounts and Balance, in ether, are the funded accounts of a simulated
+	// network
+	Accounts int    `mapstructure:"accounts"`
+	Balance  string `mapstructure:"balance"`
+
+	// Libraries maps library names, or "file.sol:Name", to the addresses of
+	// already deployed libraries to link against
+	Libraries map[string]string `mapstructure:"libraries"`
+}
+
+func loadNetworkConfig(name string) (*networkConfig, error) {
+	key := "networks." + name
+	if !viper.IsSet(key) {
+		return nil, fmt.Errorf("Unknown network %q, check the networks section of wb.yaml", name)
+	}
+
+	config := &networkConfig{Name: name}
+	if err := viper.UnmarshalKey(key, config); err != nil {
+		return nil, err
+	}
+
+	if config.Type == "" {
+		config.Type = rpcNetwork
+	}
+
+	return config, config.validate()
+}
+
+func (n *networkConfig) validate() error {
+	key := "networks." + n.Name
+	if !contains(networkTypes, n.Type) {
+		return fmt.Errorf("%s.type: must be one of %s", key, strings.Join(networkTypes, ", "))
+	}
+
+	if n.ChainID < 0 {
+		return fmt.Errorf("%s.chain_id: must not be negative", key)
+	}
+
+	if n.GasPrice != "" {
+		if _, err := gweiToWei(n.GasPrice); err != nil {
+			return fmt.Errorf("%s.gas_price: %v", key, err)
+		}
+	}
+
+	if n.Deployer != "" && !common.IsHexAddress(n.Deployer) {
+		if i, err := strconv.Atoi(n.Deployer); err != nil || i < 0 {
+			return fmt.Errorf("%s.deployer: must be an address or an index into the keystore", key)
+		}
+	}
+
+	if n.Accounts < 0 {
+		return fmt.Errorf("%s.accounts: must not be negative", key)
+	}
+
+	if n.Balance != "" {
+		if _, err := etherToWei(n.Balance); err != nil {
+			return fmt.Errorf("%s.balance: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *networkConfig) simulated() bool {
+	return n.Type == simulatedNetwork
+}
+
+// simulatedConfig is the genesis state of a simulated network
+func (n *networkConfig) simulatedConfig() (simulated.Config, error) {
+	config := simulated.Config{Accounts: n.Accounts}
+	if n.Balance != "" {
+		balance, err := etherToWei(n.Balance)
+		if err != nil {
+			return config, err
+		}
+		config.Balance = balance
+	}
+
+	return config, nil
+}
+
+// etherToWei parses an amount of ether, which may have a fractional part
+func etherToWei(ether string) (*big.Int, error) {
+	return toWei(ether, "ether", big.NewInt(1e18))
+}
+
+// gweiToWei parses an amount of gwei, which may have a fractional part
+func gweiToWei(gwei string) (*big.Int, error) {
+	return toWei(gwei, "gwei", big.NewInt(1e9))
+}
+
+func toWei(amount, unit string, weiPerUnit *big.Int) (*big.Int, error) {
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount of %s %q", unit, amount)
+	}
+
+	wei := value.Mul(value, new(big.Rat).SetInt(weiPerUnit))
+	if !wei.IsInt() {
+		return nil, fmt.Errorf("%q %s is a fraction of a wei", amount, unit)
+	}
+
+	return wei.Num(), nil
+}
+
+// dial connects to the network, and checks it's on the configured chain
+func (n *networkConfig) dial(ctx context.Context) (*ethclient.Client, *big.Int, error) {
+	if n.simulated() {
+		return nil, nil, fmt.Errorf("Network %q is simulated, so only exists while `wb test` runs", n.Name)
+	}
+
+	if n.URL == "" {
+		return nil, nil, fmt.Errorf("networks.%s.url: required to connect to the network", n.Name)
+	}
+
+	client, err := ethclient.DialContext(ctx, n.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	if err := n.checkChainID(chainID); err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	return client, chainID, nil
+}
+
+// checkChainID returns an error if the network is configured for a different
+// chain than the one its node is on
+func (n *networkConfig) checkChainID(chainID *big.Int) error {
+	if n.ChainID == 0 || chainID.Cmp(big.NewInt(n.ChainID)) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("Network %s is configured for chain %d, but %s is on chain %s", n.Name, n.ChainID, n.URL, chainID)
+}
+
+// transactor connects to the network and unlocks its deployer, the account
+// the network's deployer setting names or else the first in its keystore, for
+// sending transactions
+func (n *networkConfig) transactor(ctx context.Context) (*ethclient.Client, *bind.TransactOpts, error) {
+	client, chainID, err := n.dial(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ks, err := n.keystore()
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	account, err := deployer.Select(ks.Accounts(), n.Deployer)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	passphrase, err := n.passphrase(fmt.Sprintf("Passphrase for %s: ", account.Address.Hex()))
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	if err := ks.Unlock(account, passphrase); err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	auth, err := bind.NewKeyStoreTransactorWithChainID(ks, account, chainID)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	auth.Context = ctx
+	auth.GasLimit = n.GasLimit
+	if n.GasPrice != "" {
+		auth.GasPrice, _ = gweiToWei(n.GasPrice)
+	}
+
+	return client, auth, nil
+}
+
+// keystore opens the network's keystore
+func (n *networkConfig) keystore() (*keystore.KeyStore, error) {
+	if n.simulated() {
+		return nil, fmt.Errorf("Network %q is simulated, its accounts are generated", n.Name)
+	}
+
+	if n.Keystore == "" {
+		return nil, fmt.Errorf("networks.%s.keystore: required for the network's accounts", n.Name)
+	}
+
+	return keystore.NewKeyStore(n.Keystore, keystore.StandardScryptN, keystore.StandardScryptP), nil
+}
+
+// passphrase returns the passphrase given through the environment or the
+// network's password file, or else prompts for it
+func (n *networkConfig) passphrase(message string) (string, error) {
+	passphrase, ok, err := deployer.Password()
+	if err != nil || ok {
+		return passphrase, err
+	}
+
+	if n.PasswordFile != "" {
+		return deployer.ReadPasswordFile(n.PasswordFile)
+	}
+
+	return prompt.Stdin.PromptPassword(message)
+}
+
+// deployerEnv sets the environment the stub's migrations pick and unlock
+// their deployer by
+func (n *networkConfig) deployerEnv() error {
+	if err := os.Setenv(deployer.AccountEnv, n.Deployer); err != nil {
+		return err
+	}
+
+	_, hasPassword := os.LookupEnv(deployer.PasswordEnv)
+	if n.PasswordFile == "" || hasPassword || os.Getenv(deployer.PasswordFileEnv) != "" {
+		return nil
+	}
+
+	path, err := filepath.Abs(n.PasswordFile)
+	if err != nil {
+		return err
+	}
+
+	return os.Setenv(deployer.PasswordFileEnv, path)
+}
+
+// networkNames returns the names of the configured networks, sorted
+func networkNames() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap("networks") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func listNetworks() error {
+	names := networkNames()
+	if len(names) == 0 {
+		fmt.Println("No networks, add one with `wb network add`")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tURL\tCHAIN ID\tKEYSTORE")
+	for _, name := range names {
+		config, err := loadNetworkConfig(name)
+		if err != nil {
+			return err
+		}
+
+		url, chainID := config.URL, "any"
+		if config.simulated() {
+			url, chainID = "in process", "-"
+		} else if config.ChainID != 0 {
+			chainID = strconv.FormatInt(config.ChainID, 10)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, config.Type, orNone(url), chainID, orNone(config.Keystore))
+	}
+
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// networkFromFlags is the network `wb network add` is given
+func networkFromFlags(cmd *cobra.Command, name string) (*networkConfig, error) {
+	if strings.Contains(name, ".") {
+		return nil, fmt.Errorf("Invalid network name %q, it can't contain a dot", name)
+	}
+
+	flags := cmd.Flags()
+	config := &networkConfig{Name: name}
+	config.Type, _ = flags.GetString("type")
+	config.URL, _ = flags.GetString("url")
+	config.Keystore, _ = flags.GetString("keystore")
+	config.ChainID, _ = flags.GetInt64("chain-id")
+	config.GasPrice, _ = flags.GetString("gas-price")
+	config.GasLimit, _ = flags.GetUint64("gas-limit")
+	config.Deployer, _ = flags.GetString("deployer")
+	config.PasswordFile, _ = flags.GetString("password-file")
+	config.Accounts, _ = flags.GetInt("accounts")
+	config.Balance, _ = flags.GetString("balance")
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.simulated() {
+		if config.URL != "" || config.Keystore != "" || config.ChainID != 0 || config.Deployer != "" || config.PasswordFile != "" {
+			return nil, errors.New("A simulated network has no url, keystore, chain id or deployer")
+		}
+	} else {
+		if config.URL == "" {
+			return nil, errors.New("A network needs a --url")
+		}
+		if config.Accounts != 0 || config.Balance != "" {
+			return nil, errors.New("Only simulated networks have funded --accounts and --balance")
+		}
+	}
+
+	return config, nil
+}
+
+func addNetwork(config *networkConfig, force bool) error {
+	return editConfig(func(root *yaml.Node) error {
+		networks, err := configMapping(root, "networks")
+		if err != nil {
+			return err
+		}
+
+		if configIndex(networks, config.Name) >= 0 {
+			if !force {
+				return fmt.Errorf("Network %q already exists, replace it with --force", config.Name)
+			}
+			removeConfig(networks, config.Name)
+		}
+
+		network, err := configMapping(networks, config.Name)
+		if err != nil {
+			return err
+		}
+
+		if config.simulated() {
+			setConfig(network, "type", "!!str", config.Type)
+		}
+		if config.URL != "" {
+			setConfig(network, "url", "!!str", config.URL)
+		}
+		if config.Keystore != "" {
+			setConfig(network, "keystore", "!!str", config.Keystore)
+		}
+		if config.ChainID != 0 {
+			setConfig(network, "chain_id", "!!int", strconv.FormatInt(config.ChainID, 10))
+		}
+		if config.GasPrice != "" {
+			setConfig(network, "gas_price", "", config.GasPrice)
+		}
+		if config.GasLimit != 0 {
+			setConfig(network, "gas_limit", "!!int", strconv.FormatUint(config.GasLimit, 10))
+		}
+		if config.Deployer != "" {
+			setConfig(network, "deployer", "!!str", config.Deployer)
+		}
+		if config.PasswordFile != "" {
+			setConfig(network, "password_file", "!!str", config.PasswordFile)
+		}
+		if config.Accounts != 0 {
+			setConfig(network, "accounts", "!!int", strconv.Itoa(config.Accounts))
+		}
+		if config.Balance != "" {
+			setConfig(network, "balance", "", config.Balance)
+		}
+
+		return nil
+	})
+}
+
+func removeNetwork(name string) error {
+	return editConfig(func(root *yaml.Node) error {
+		networks, err := configMapping(root, "networks")
+		if err != nil {
+			return err
+		}
+
+		if !removeConfig(networks, name) {
+			return fmt.Errorf("Unknown network %q, check the networks section of wb.yaml", name)
+		}
+
+		return nil
+	})
+}
+
+// checkNetworks connects to each network and reports the chain it's on,
+// failing if any is unreachable or on the wrong chain
+func checkNetworks(names []string, timeout time.Duration) error {
+	checkAll := len(names) == 0
+	if checkAll {
+		names = networkNames()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	checked, failed := 0, 0
+	for _, name := range names {
+		config, err := loadNetworkConfig(name)
+		if err != nil {
+			return err
+		}
+
+		if config.simulated() || config.URL == "" {
+			if !checkAll {
+				return fmt.Errorf("Network %q has no url to connect to", name)
+			}
+			continue
+		}
+
+		checked++
+		chainID, block, err := config.check(timeout)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\t%s\tfailed: %v\n", name, config.URL, err)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\tchain %s\tblock %d\tok\n", name, config.URL, chainID, block)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d networks failed the check", failed, checked)
+	}
+
+	return nil
+}
+
+// check connects to the network and returns its chain id and latest block
+func (n *networkConfig) check(timeout time.Duration) (*big.Int, uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, chainID, err := n.dial(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer client.Close()
+
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return chainID, block, nil
+}
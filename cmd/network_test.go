@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestToWei(t *testing.T) {
+	tests := []struct {
+		convert func(string) (*big.Int, error)
+		amount  string
+		want    string
+		err     bool
+	}{
+		{etherToWei, "1", "1000000000000000000", false},
+		{etherToWei, "0", "0", false},
+		{etherToWei, "0.5", "500000000000000000", false},
+		{etherToWei, "100000", "100000000000000000000000", false},
+		{etherToWei, "0.000000000000000001", "1", false},
+		{etherToWei, "0.0000000000000000001", "", true},
+		{etherToWei, "-1", "", true},
+		{etherToWei, "lots", "", true},
+		{etherToWei, "", "", true},
+		{gweiToWei, "1", "1000000000", false},
+		{gweiToWei, "2.5", "2500000000", false},
+		{gweiToWei, "0.000000001", "1", false},
+		{gweiToWei, "0.0000000001", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := test.convert(test.amount)
+		if (err != nil) != test.err {
+			t.Errorf("converting %q: error = %v, want error %v", test.amount, err, test.err)
+			continue
+		}
+
+		if err == nil && got.String() != test.want {
+			t.Errorf("converting %q = %s, want %s", test.amount, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/deployer"
+	"github.com/zscole/cli/simulated"
+)
+
+var nodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Run a local development chain with a JSON-RPC server",
+	Long: `Starts an in-process simulated chain and serves it over JSON-RPC on HTTP and
+IPC, for dapps, wallets and scripts to develop against. The chain starts from
+genesis every time, with funded accounts that are the same on every run.
+
+The accounts are also saved to ` + nodeKeystore + ` with an empty passphrase, a
+keystore of the node's own that only ever holds them. When the network the node
+stands in for, dev unless configured, uses that keystore, ` + "`wb migrate`" + ` deploys
+with them; any other keystore is left alone.
+
+Deployments recorded for the network belong to an earlier chain, and are
+cleared with --reset.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var config *nodeConfig
+		err := RunInRoot(func() error {
+			var err error
+			config, err = loadNodeConfig()
+			return err
+		})
+		if err != nil {
+			Fatal(err)
+		}
+
+		if err := config.applyFlags(cmd); err != nil {
+			Fatal(err)
+		}
+
+		if err := RunInRoot(func() error { return runNode(config) }); err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(nodeCmd)
+
+	nodeCmd.Flags().String("http", "", "address to serve JSON-RPC over HTTP on, or none")
+	nodeCmd.Flags().String("ipc", "", "path of the socket to serve JSON-RPC over IPC on, or none")
+	nodeCmd.Flags().Int64("chain-id", 0, "chain id of the chain")
+	nodeCmd.Flags().Duration("block-time", 0, "time between blocks, or 0 to mine each transaction at once")
+	nodeCmd.Flags().Int("accounts", 0, "number of funded accounts")
+	nodeCmd.Flags().String("balance", "", "ether each account is funded with")
+	nodeCmd.Flags().Bool("migrate", false, "run the migrations once the node is serving")
+	nodeCmd.Flags().Bool("reset", false, "clear the deployments recorded for the network first")
+}
+
+const (
+	defaultNodeHTTP    = "127.0.0.1:8545"
+	defaultNodeIPC     = "build/node.ipc"
+	defaultNodeNetwork = "dev"
+
+	// nodeKeystore is where the node saves its accounts. It's kept apart from
+	// any other keystore, since the keys are public
+	nodeKeystore = "build/node/keystore"
+
+	// noEndpoint turns off serving over HTTP or IPC
+	noEndpoint = "none"
+)
+
+// nodeConfig is the `node:` section of wb.yaml
+type nodeConfig struct {
+	// Network is the network in wb.yaml the node stands in for, whose
+	// keystore gets the accounts and that migrations run on
+	Network string `mapstructure:"network"`
+
+	HTTP      string        `mapstructure:"http"`
+	IPC       string        `mapstructure:"ipc"`
+	ChainID   int64         `mapstructure:"chain_id"`
+	BlockTime time.Duration `mapstructure:"block_time"`
+	Accounts  int           `mapstructure:"accounts"`
+	Balance   string        `mapstructure:"balance"`
+	Migrate   bool          `mapstructure:"migrate"`
+
+	// Reset clears the deployments recorded for the network on start
+	Reset bool `mapstructure:"reset"`
+}
+
+func loadNodeConfig() (*nodeConfig, error) {
+	config := &nodeConfig{}
+	if err := viper.UnmarshalKey("node", config); err != nil {
+		return nil, err
+	}
+
+	if config.Network == "" {
+		config.Network = defaultNodeNetwork
+	}
+	if config.HTTP == "" {
+		config.HTTP = defaultNodeHTTP
+	}
+	if config.IPC == "" {
+		config.IPC = defaultNodeIPC
+	}
+
+	return config, config.validate()
+}
+
+// applyFlags overrides the config with any flags given on the command line
+func (c *nodeConfig) applyFlags(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	if flags.Changed("http") {
+		c.HTTP, _ = flags.GetString("http")
+	}
+	if flags.Changed("ipc") {
+		c.IPC, _ = flags.GetString("ipc")
+	}
+	if flags.Changed("chain-id") {
+		c.ChainID, _ = flags.GetInt64("chain-id")
+	}
+	if flags.Changed("block-time") {
+		c.BlockTime, _ = flags.GetDuration("block-time")
+	}
+	if flags.Changed("accounts") {
+		c.Accounts, _ = flags.GetInt("accounts")
+	}
+	if flags.Changed("balance") {
+		c.Balance, _ = flags.GetString("balance")
+	}
+	if flags.Changed("migrate") {
+		c.Migrate, _ = flags.GetBool("migrate")
+	}
+	if flags.Changed("reset") {
+		c.Reset, _ = flags.GetBool("reset")
+	}
+
+	return c.validate()
+}
+
+func (c *nodeConfig) validate() error {
+	if c.ChainID < 0 {
+		return fmt.Errorf("node.chain_id: must not be negative")
+	}
+	if c.BlockTime < 0 {
+		return fmt.Errorf("node.block_time: must not be negative")
+	}
+	if c.Accounts < 0 {
+		return fmt.Errorf("node.accounts: must not be negative")
+	}
+	if c.Balance != "" {
+		if _, err := etherToWei(c.Balance); err != nil {
+			return fmt.Errorf("node.balance: %v", err)
+		}
+	}
+	if c.HTTP == noEndpoint && c.IPC == noEndpoint {
+		return fmt.Errorf("node: must serve over at least one of http and ipc")
+	}
+
+	return nil
+}
+
+func (c *nodeConfig) chainConfig() simulated.Config {
+	// Dry runs against the node check the storage they fork with proofs
+	config := simulated.Config{Accounts: c.Accounts, Manual: c.BlockTime > 0, Proofs: true}
+	if c.ChainID != 0 {
+		config.ChainID = big.NewInt(c.ChainID)
+	}
+	if c.Balance != "" {
+		config.Balance, _ = etherToWei(c.Balance)
+	}
+
+	return config
+}
+
+func runNode(config *nodeConfig) error {
+	chain, err := simulated.New(config.chainConfig())
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+
+	server, err := simulated.NewServer(chain)
+	if err != nil {
+		return err
+	}
+	defer server.Stop()
+
+	network, err := nodeNetwork(config.Network)
+	if err != nil {
+		return err
+	}
+
+	if config.Reset {
+		if err := resetDeployments(config.Network); err != nil {
+			return err
+		}
+	}
+
+	if err := saveNodeAccounts(chain, nodeKeystore); err != nil {
+		return err
+	}
+
+	errs := make(chan error, 2)
+	if config.HTTP != noEndpoint {
+		listener, err := net.Listen("tcp", config.HTTP)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		go func() { errs <- http.Serve(listener, server) }()
+	}
+
+	if config.IPC != noEndpoint {
+		// A socket left by a node that didn't shut down cleanly is stale
+		os.Remove(config.IPC)
+		if err := os.MkdirAll(filepath.Dir(config.IPC), os.FileMode(0755)); err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("unix", config.IPC)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(config.IPC)
+		defer listener.Close()
+
+		go func() { errs <- server.ServeListener(listener) }()
+	}
+
+	printNode(config, chain, network)
+
+	if config.BlockTime > 0 {
+		ticker := time.NewTicker(config.BlockTime)
+		defer ticker.Stop()
+
+		go func() {
+			for range ticker.C {
+				server.Mine()
+			}
+		}()
+	}
+
+	if config.Migrate {
+		// The node's accounts have an empty passphrase
+		_, ok := os.LookupEnv(deployer.PasswordEnv)
+		if !ok && usesNodeKeystore(network) && network.PasswordFile == "" {
+			os.Setenv(deployer.PasswordEnv, "")
+		}
+
+		if err := runMigrations(config.Network, false); err != nil {
+			fmt.Println("Error: migrations failed:", err)
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-signals:
+		fmt.Println("\nStopping node")
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+// nodeNetwork returns the config of the network the node stands in for, or
+// nil if wb.yaml has none
+func nodeNetwork(name string) (*networkConfig, error) {
+	if !viper.IsSet("networks." + name) {
+		return nil, nil
+	}
+
+	network, err := loadNetworkConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if network.simulated() {
+		return nil, fmt.Errorf("node.network: %q is simulated, the node should stand in for a network with a url", name)
+	}
+
+	return network, nil
+}
+
+// usesNodeKeystore returns whether a network deploys from the node's accounts
+func usesNodeKeystore(network *networkConfig) bool {
+	return network != nil && network.Keystore != "" && filepath.Clean(network.Keystore) == nodeKeystore
+}
+
+// saveNodeAccounts adds the node's accounts to a keystore, with an empty
+// passphrase. The keys are public anyway, so they're encrypted as cheaply as
+// possible
+func saveNodeAccounts(chain *simulated.Chain, dir string) error {
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	for _, account := range chain.Accounts() {
+		if ks.HasAddress(account) {
+			continue
+		}
+
+		if _, err := ks.ImportECDSA(chain.PrivateKey(account), ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printNode(config *nodeConfig, chain *simulated.Chain, network *networkConfig) {
+	fmt.Println("Chain id:", chain.ChainID())
+	if config.BlockTime > 0 {
+		fmt.Println("Block time:", config.BlockTime)
+	} else {
+		fmt.Println("Block time: mining each transaction at once")
+	}
+
+	if config.HTTP != noEndpoint {
+		fmt.Println("HTTP:", "http://"+config.HTTP)
+	}
+	if config.IPC != noEndpoint {
+		fmt.Println("IPC:", config.IPC)
+	}
+
+	fmt.Println()
+	fmt.Println("Accounts, whose keys are public so must never be used on a real network:")
+	balance := weiToEther(config.chainConfig().Balance)
+	for i, account := range chain.Accounts() {
+		fmt.Printf("  (%d) %s %s ether\n", i, account.Hex(), balance)
+		fmt.Printf("      key 0x%s\n", common.Bytes2Hex(crypto.FromECDSA(chain.PrivateKey(account))))
+	}
+
+	fmt.Println()
+	fmt.Printf("Saved to %s with an empty passphrase\n", nodeKeystore)
+	if network != nil {
+		if !usesNodeKeystore(network) {
+			fmt.Printf("Network %s deploys from %s instead, set its keystore to %s to use them\n", network.Name, orNone(network.Keystore), nodeKeystore)
+		}
+		if network.URL != "http://"+config.HTTP && network.URL != config.IPC {
+			fmt.Printf("Warning: network %s is at %s, which the node isn't serving\n", network.Name, network.URL)
+		}
+	}
+	fmt.Println()
+}
+
+// weiToEther formats an amount of wei in ether, the default balance if nil
+func weiToEther(wei *big.Int) string {
+	if wei == nil {
+		wei = simulated.DefaultBalance
+	}
+
+	return formatUnits(wei, big.NewInt(1e18))
+}
+
+// formatUnits formats an amount of wei in a larger unit, without trailing
+// zeros
+func formatUnits(wei, weiPerUnit *big.Int) string {
+	digits := len(weiPerUnit.String()) - 1
+	amount := new(big.Rat).SetFrac(wei, weiPerUnit).FloatString(digits)
+	return strings.TrimSuffix(strings.TrimRight(amount, "0"), ".")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zscole/cli/simulated"
+)
+
+func TestWeiToEther(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want string
+	}{
+		{"0", "0"},
+		{"1", "0.000000000000000001"},
+		{"1000000000000000000", "1"},
+		{"1500000000000000000", "1.5"},
+		{"100000000000000000000000", "100000"},
+		{"123456789012345678901", "123.456789012345678901"},
+	}
+
+	for _, test := range tests {
+		wei, _ := new(big.Int).SetString(test.wei, 10)
+		if got := weiToEther(wei); got != test.want {
+			t.Errorf("weiToEther(%s) = %s, want %s", test.wei, got, test.want)
+		}
+	}
+
+	if got, want := weiToEther(nil), weiToEther(simulated.DefaultBalance); got != want {
+		t.Errorf("weiToEther(nil) = %s, want the default balance %s", got, want)
+	}
+}
+
+func TestWeiToEtherRoundTrip(t *testing.T) {
+	for _, ether := range []string{"0", "0.25", "1", "42.000000000000000001", "1000000"} {
+		wei, err := etherToWei(ether)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := weiToEther(wei); got != ether {
+			t.Errorf("weiToEther(etherToWei(%s)) = %s", ether, got)
+		}
+	}
+}
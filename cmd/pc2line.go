@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var pc2lineCmd = &cobra.Command{
+	Use:   "pc2line CONTRACT PC",
+	Short: "Map a program counter in a contract to its line of source",
+	Long: `Maps a program counter, such as one from a revert or a trace, to the file,
+line and source of the code the compiler generated it from. The program counter
+is in the deployed code, or in the code creating the contract with --init, and
+may be decimal or hex with a 0x prefix.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			Fatal("Must specify contract name and program counter")
+		}
+
+		pc, err := strconv.ParseUint(args[1], 0, 32)
+		if err != nil {
+			Fatal(fmt.Sprintf("Invalid program counter %q", args[1]))
+		}
+
+		init, _ := cmd.Flags().GetBool("init")
+
+		err = RunInRoot(func() error {
+			return pc2line(os.Stdout, args[0], int(pc), init)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(pc2lineCmd)
+
+	pc2lineCmd.Flags().Bool("init", false, "the program counter is in the code creating the contract")
+}
+
+// sourceRange is an entry of a decompressed source map, the bytes of a source
+// an instruction was generated from. File is -1 for code that doesn't
+// correspond to any source
+type sourceRange struct {
+	Start  int
+	Length int
+	File   int
+	Jump   string
+}
+
+func pc2line(w io.Writer, name string, pc int, init bool) error {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return fmt.Errorf("No artifacts for %s, build it with `wb compile`", name)
+	}
+
+	bytecode, sourceMap := a.DeployedBytecode, a.DeployedSourceMap
+	if init {
+		bytecode, sourceMap = a.Bytecode, a.SourceMap
+	}
+
+	if bytecode == "" || sourceMap == "" || len(a.SourceList) == 0 {
+		return fmt.Errorf("No source map for %s, rebuild it with `wb compile`", name)
+	}
+
+	index, err := instructionIndex(bytecode, pc)
+	if err != nil {
+		return err
+	}
+
+	ranges := decodeSourceMap(sourceMap)
+	if index >= len(ranges) {
+		return fmt.Errorf("pc %d is past the end of the source map of %s", pc, name)
+	}
+
+	r := ranges[index]
+	if r.File < 0 || r.File >= len(a.SourceList) {
+		fmt.Fprintf(w, "pc %d of %s is in code generated by the compiler\n", pc, name)
+		return nil
+	}
+
+	config, err := loadCompilerConfig()
+	if err != nil {
+		return err
+	}
+
+	unit := a.SourceList[r.File]
+	path := locateSource(unit, config.IncludePaths)
+	if path == "" {
+		return fmt.Errorf("Can't find %s, which pc %d of %s is in", unit, pc, name)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if r.Start+r.Length > len(content) {
+		return fmt.Errorf("%s has changed since %s was built, rebuild it with `wb compile`", path, name)
+	}
+
+	return printSnippet(w, path, content, r.Start, r.Length)
+}
+
+// instructionIndex returns which instruction of the bytecode starts at a
+// program counter. Instructions are counted rather than bytes because source
+// maps have an entry per instruction
+func instructionIndex(bytecode string, pc int) (int, error) {
+	// Library placeholders stand in for 20 byte addresses
+	code, err := hex.DecodeString(strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return '0'
+	}, bytecode))
+	if err != nil {
+		return 0, err
+	}
+
+	index := 0
+	for i := 0; i < len(code); i++ {
+		if i == pc {
+			return index, nil
+		}
+
+		// PUSH1 to PUSH32 are followed by 1 to 32 bytes of data
+		op := code[i]
+		if op >= 0x60 && op <= 0x7f {
+			push := int(op-0x60) + 1
+			if pc > i && pc <= i+push {
+				return 0, fmt.Errorf("pc %d is inside the data of the PUSH%d at pc %d", pc, push, i)
+			}
+			i += push
+		}
+
+		index++
+	}
+
+	return 0, fmt.Errorf("pc %d is past the end of the code, which is %d bytes", pc, len(code))
+}
+
+// decodeSourceMap decompresses a solc source map, where each entry is
+// "start:length:file:jump:modifierDepth" with any field that's left out taking
+// the value of the entry before
+func decodeSourceMap(sourceMap string) []sourceRange {
+	entries := strings.Split(sourceMap, ";")
+	ranges := make([]sourceRange, 0, len(entries))
+
+	current := sourceRange{File: -1}
+	for _, entry := range entries {
+		fields := strings.Split(entry, ":")
+
+		if len(fields) > 0 && fields[0] != "" {
+			current.Start, _ = strconv.Atoi(fields[0])
+		}
+		if len(fields) > 1 && fields[1] != "" {
+			current.Length, _ = strconv.Atoi(fields[1])
+		}
+		if len(fields) > 2 && fields[2] != "" {
+			current.File, _ = strconv.Atoi(fields[2])
+		}
+		if len(fields) > 3 && fields[3] != "" {
+			current.Jump = fields[3]
+		}
+
+		ranges = append(ranges, current)
+	}
+
+	return ranges
+}
+
+// printSnippet prints the location of a range of a source, and its first line
+// with the range underlined
+func printSnippet(w io.Writer, path string, content []byte, start, length int) error {
+	if start < 0 || length < 0 {
+		return errors.New("Invalid source range")
+	}
+
+	line, column := lineColumn(content, start)
+	fmt.Fprintf(w, "%s:%d:%d\n", path, line, column)
+
+	lineStart := start - (column - 1)
+	lineEnd := lineStart
+	for lineEnd < len(content) && content[lineEnd] != '\n' {
+		lineEnd++
+	}
+
+	text := strings.Replace(string(content[lineStart:lineEnd]), "\t", " ", -1)
+
+	// Ranges spanning several lines are underlined to the end of the first
+	width := length
+	if start+width > lineEnd {
+		width = lineEnd - start
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	gutter := strconv.Itoa(line)
+	fmt.Fprintf(w, "%s | %s\n", gutter, text)
+	fmt.Fprintf(w, "%s | %s%s\n", strings.Repeat(" ", len(gutter)), strings.Repeat(" ", column-1), strings.Repeat("^", width))
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeSourceMap(t *testing.T) {
+	// Left out fields take the value of the entry before, and an empty entry
+	// repeats it whole
+	got := decodeSourceMap("0:10:0:-;;5:2;:3:1:i;::-1:o;7;::::1")
+	want := []sourceRange{
+		{Start: 0, Length: 10, File: 0, Jump: "-"},
+		{Start: 0, Length: 10, File: 0, Jump: "-"},
+		{Start: 5, Length: 2, File: 0, Jump: "-"},
+		{Start: 5, Length: 3, File: 1, Jump: "i"},
+		{Start: 5, Length: 3, File: -1, Jump: "o"},
+		{Start: 7, Length: 3, File: -1, Jump: "o"},
+		{Start: 7, Length: 3, File: -1, Jump: "o"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeSourceMap() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInstructionIndex(t *testing.T) {
+	// PUSH1 0x80, PUSH1 0x40, MSTORE, PUSH32 of 0xff bytes, PUSH20 of a library
+	// placeholder, STOP
+	placeholder := "__$" + strings.Repeat("a", 34) + "$__"
+	bytecode := "6080" + "6040" + "52" + "7f" + strings.Repeat("ff", 32) + "73" + placeholder + "00"
+
+	tests := []struct {
+		pc    int
+		index int
+		err   string
+	}{
+		{pc: 0, index: 0},
+		{pc: 2, index: 1},
+		{pc: 4, index: 2},
+		{pc: 5, index: 3},
+		{pc: 38, index: 4},
+		{pc: 59, index: 5},
+		{pc: 1, err: "inside the data of the PUSH1 at pc 0"},
+		{pc: 6, err: "inside the data of the PUSH32 at pc 5"},
+		{pc: 37, err: "inside the data of the PUSH32 at pc 5"},
+		{pc: 50, err: "inside the data of the PUSH20 at pc 38"},
+		{pc: 60, err: "past the end of the code, which is 60 bytes"},
+	}
+
+	for _, test := range tests {
+		index, err := instructionIndex(bytecode, test.pc)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("instructionIndex(%d) = %d, %v, want error %q", test.pc, index, err, test.err)
+			}
+			continue
+		}
+
+		if err != nil || index != test.index {
+			t.Errorf("instructionIndex(%d) = %d, %v, want %d", test.pc, index, err, test.index)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var selectorsCmd = &cobra.Command{
+	Use:   "selectors CONTRACT",
+	Short: "Show the function selectors and event topics of a contract",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			Fatal("Must specify contract name")
+		}
+
+		err := RunInRoot(func() error {
+			selectors, err := contractSelectors(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printSelectors(os.Stdout, selectors)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(selectorsCmd)
+}
+
+// proxyConfig is an entry of the `proxies:` section of wb.yaml, a group of
+// contracts whose functions are combined behind one address, such as a proxy
+// and its implementation or a diamond and its facets
+type proxyConfig struct {
+	Name      string   `mapstructure:"name"`
+	Contracts []string `mapstructure:"contracts"`
+}
+
+func loadProxyConfig() ([]proxyConfig, error) {
+	proxies := make([]proxyConfig, 0)
+	if err := viper.UnmarshalKey("proxies", &proxies); err != nil {
+		return nil, err
+	}
+
+	for i, proxy := range proxies {
+		if proxy.Name == "" {
+			return nil, fmt.Errorf("proxies[%d]: must specify a name", i)
+		}
+
+		if len(proxy.Contracts) < 2 {
+			return nil, fmt.Errorf("proxies[%d]: must list at least two contracts", i)
+		}
+	}
+
+	return proxies, nil
+}
+
+// selector identifies a function by the first four bytes of the hash of its
+// signature, or an event by the whole hash
+type selector struct {
+	Contract  string
+	Event     bool
+	Signature string
+	ID        string
+}
+
+func contractSelectors(name string) ([]selector, error) {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return nil, fmt.Errorf("No artifacts for %s, build it with `wb compile`", name)
+	}
+
+	entries, err := parseABI(a.ABI)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	selectors := make([]selector, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type != "function" && entry.Type != "event" {
+			continue
+		}
+
+		selectors = append(selectors, selector{
+			Contract:  name,
+			Event:     entry.Type == "event",
+			Signature: entry.signature(),
+			ID:        common.Bytes2Hex(entry.id),
+		})
+	}
+
+	sort.Slice(selectors, func(i, j int) bool {
+		if selectors[i].Event != selectors[j].Event {
+			return !selectors[i].Event
+		}
+		return selectors[i].ID < selectors[j].ID
+	})
+
+	return selectors, nil
+}
+
+func printSelectors(w io.Writer, selectors []selector) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Kind\tSelector\tSignature")
+
+	for _, s := range selectors {
+		kind := "function"
+		if s.Event {
+			kind = "event"
+		}
+
+		fmt.Fprintf(tw, "%s\t0x%s\t%s\n", kind, s.ID, s.Signature)
+	}
+
+	return tw.Flush()
+}
+
+// checkSelectorCollisions fails if any two contracts of a proxy group share a
+// function selector, since calls to one would silently reach the other, or
+// if two different events share a topic
+func checkSelectorCollisions() error {
+	proxies, err := loadProxyConfig()
+	if err != nil {
+		return err
+	}
+
+	collisions := make([]string, 0)
+	for _, proxy := range proxies {
+		byID := make(map[string][]selector)
+		for _, contract := range proxy.Contracts {
+			selectors, err := contractSelectors(contract)
+			if err != nil {
+				return fmt.Errorf("proxy %s: %v", proxy.Name, err)
+			}
+
+			for _, s := range selectors {
+				key := s.ID
+				if s.Event {
+					key = "event:" + s.ID
+				}
+				byID[key] = append(byID[key], s)
+			}
+		}
+
+		keys := make([]string, 0, len(byID))
+		for key := range byID {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if clash := describeCollision(byID[key]); clash != "" {
+				collisions = append(collisions, fmt.Sprintf("proxy %s: %s", proxy.Name, clash))
+			}
+		}
+	}
+
+	if len(collisions) > 0 {
+		return errors.New("Selector collisions detected:\n" + strings.Join(collisions, "\n"))
+	}
+
+	return nil
+}
+
+// describeCollision describes selectors sharing an ID if they clash, or
+// returns an empty string. An event declared by several contracts is the
+// same event, but a function in several contracts is ambiguous whatever its
+// signature
+func describeCollision(selectors []selector) string {
+	if len(selectors) < 2 {
+		return ""
+	}
+
+	names := make([]string, 0, len(selectors))
+	signatures := make(map[string]bool)
+	for _, s := range selectors {
+		names = append(names, s.Contract+"."+s.Signature)
+		signatures[s.Signature] = true
+	}
+
+	if selectors[0].Event {
+		if len(signatures) < 2 {
+			return ""
+		}
+		return fmt.Sprintf("event topic 0x%s is shared by %s", selectors[0].ID, strings.Join(names, ", "))
+	}
+
+	return fmt.Sprintf("selector 0x%s is shared by %s", selectors[0].ID, strings.Join(names, ", "))
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/project"
+)
+
+// writeABI saves a contract's artifact with an abi of the given functions
+// and events, given as solidity signatures with unnamed parameters
+func writeABI(t *testing.T, name string, functions, events []string) {
+	t.Helper()
+
+	entries := make([]string, 0)
+	member := func(kind, signature string) string {
+		open := strings.Index(signature, "(")
+		inputs := make([]string, 0)
+		for _, typ := range strings.Split(strings.Trim(signature[open:], "()"), ",") {
+			if typ != "" {
+				inputs = append(inputs, `{"name":"","type":"`+typ+`"}`)
+			}
+		}
+		return `{"type":"` + kind + `","name":"` + signature[:open] + `","inputs":[` + strings.Join(inputs, ",") + `],"outputs":[],"stateMutability":"nonpayable","anonymous":false}`
+	}
+	for _, f := range functions {
+		entries = append(entries, member("function", f))
+	}
+	for _, e := range events {
+		entries = append(entries, member("event", e))
+	}
+
+	a := &artifact{ContractName: name, ABI: []byte("[" + strings.Join(entries, ",") + "]")}
+	if err := writeArtifact(a, &artifactsConfig{Format: splitArtifacts}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckSelectorCollisions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(project.BuildDirectory, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { viper.Set("proxies", nil) })
+
+	tests := []struct {
+		name           string
+		proxy, logic   []string
+		proxyEvents    []string
+		logicEvents    []string
+		want           string
+		wantCollisions int
+	}{
+		{
+			// Different functions whose signatures hash alike
+			name:           "known collision",
+			proxy:          []string{"collate_propagate_storage(bytes16)"},
+			logic:          []string{"burn(uint256)", "mint(uint256)"},
+			want:           "selector 0x42966c68 is shared by Proxy.collate_propagate_storage(bytes16), Logic.burn(uint256)",
+			wantCollisions: 1,
+		},
+		{
+			// The proxy's upgrade function shadows the implementation's
+			name:           "proxy and implementation clash",
+			proxy:          []string{"upgradeTo(address)", "admin()"},
+			logic:          []string{"upgradeTo(address)", "transfer(address,uint256)"},
+			want:           "selector 0x3659cfe6 is shared by Proxy.upgradeTo(address), Logic.upgradeTo(address)",
+			wantCollisions: 1,
+		},
+		{
+			name:  "overloads",
+			proxy: []string{"admin()"},
+			logic: []string{"transfer(address)", "transfer(address,uint256)", "transfer(address,uint256,bytes)"},
+		},
+		{
+			// An event both declare is the same event
+			name:        "shared event",
+			proxy:       []string{"admin()"},
+			logic:       []string{"transfer(address,uint256)"},
+			proxyEvents: []string{"Transfer(address,address,uint256)"},
+			logicEvents: []string{"Transfer(address,address,uint256)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writeABI(t, "Proxy", test.proxy, test.proxyEvents)
+			writeABI(t, "Logic", test.logic, test.logicEvents)
+			viper.Set("proxies", []map[string]interface{}{{"name": "Token", "contracts": []string{"Proxy", "Logic"}}})
+
+			err := checkSelectorCollisions()
+			if test.want == "" {
+				if err != nil {
+					t.Errorf("checkSelectorCollisions() = %v, want no collisions", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("checkSelectorCollisions() succeeded, want %q", test.want)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("checkSelectorCollisions() = %v, want %q", err, test.want)
+			}
+			if n := strings.Count(err.Error(), "\nproxy Token: "); n != test.wantCollisions {
+				t.Errorf("%d collisions reported, want %d:\n%v", n, test.wantCollisions, err)
+			}
+		})
+	}
+}
+
+func TestDescribeCollision(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors []selector
+		want      string
+	}{
+		{
+			name:      "one",
+			selectors: []selector{{Contract: "Logic", Signature: "burn(uint256)", ID: "42966c68"}},
+		},
+		{
+			name: "functions",
+			selectors: []selector{
+				{Contract: "Logic", Signature: "burn(uint256)", ID: "42966c68"},
+				{Contract: "Proxy", Signature: "collate_propagate_storage(bytes16)", ID: "42966c68"},
+			},
+			want: "selector 0x42966c68 is shared by Logic.burn(uint256), Proxy.collate_propagate_storage(bytes16)",
+		},
+		{
+			name: "same event",
+			selectors: []selector{
+				{Contract: "Logic", Event: true, Signature: "Upgraded(address)", ID: "bc7cd75a"},
+				{Contract: "Proxy", Event: true, Signature: "Upgraded(address)", ID: "bc7cd75a"},
+			},
+		},
+		{
+			name: "different events",
+			selectors: []selector{
+				{Contract: "Logic", Event: true, Signature: "A(uint256)", ID: "ff"},
+				{Contract: "Proxy", Event: true, Signature: "B(bytes32)", ID: "ff"},
+			},
+			want: "event topic 0xff is shared by Logic.A(uint256), Proxy.B(bytes32)",
+		},
+	}
+
+	for _, test := range tests {
+		if got := describeCollision(test.selectors); got != test.want {
+			t.Errorf("%s: describeCollision() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
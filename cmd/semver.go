@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// version is a solidity compiler release, e.g. 0.4.24
+type version [3]int
+
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?`)
+
+// parseVersion reads a full version from the start of a string, ignoring any
+// build metadata such as "+commit.e67f0147"
+func parseVersion(s string) (version, error) {
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return v, err
+	}
+
+	if parts != 3 {
+		return v, fmt.Errorf("Invalid version %q", s)
+	}
+
+	return v, nil
+}
+
+// parsePartialVersion reads a version that may omit or wildcard its minor and
+// patch numbers, returning how many parts were given
+func parsePartialVersion(s string) (version, int, error) {
+	var v version
+
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return v, 0, fmt.Errorf("Invalid version %q", s)
+	}
+
+	parts := 0
+	for i := 1; i <= 3; i++ {
+		n, err := strconv.Atoi(m[i])
+		if err != nil {
+			break
+		}
+
+		v[i-1] = n
+		parts++
+	}
+
+	return v, parts, nil
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+func (v version) compare(o version) int {
+	for i := range v {
+		if v[i] < o[i] {
+			return -1
+		}
+		if v[i] > o[i] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+type comparator struct {
+	op string
+	v  version
+}
+
+func (c comparator) matches(v version) bool {
+	cmp := v.compare(c.v)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// constraint is a version range as used by `pragma solidity`, a set of
+// alternatives separated by "||", each of which is a set of comparators that
+// must all match. Vyper's PEP 440 specifiers, separated by commas and with
+// "~=" and "==" operators, are read the same way
+type constraint [][]comparator
+
+var operatorPattern = regexp.MustCompile(`^(\^|~=|~|>=|<=|>|<|==|=)?\s*(.*)$`)
+
+func parseConstraint(s string) (constraint, error) {
+	c := make(constraint, 0)
+	for _, alternative := range strings.Split(s, "||") {
+		comparators, err := parseComparators(alternative)
+		if err != nil {
+			return nil, err
+		}
+
+		c = append(c, comparators)
+	}
+
+	return c, nil
+}
+
+func parseComparators(s string) ([]comparator, error) {
+	// Join operators separated from their version, e.g. ">= 0.4.22"
+	fields := strings.Fields(strings.Replace(s, ",", " ", -1))
+	terms := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], "^~<>=") == "" && i+1 < len(fields) {
+			terms = append(terms, fields[i]+fields[i+1])
+			i++
+			continue
+		}
+
+		terms = append(terms, fields[i])
+	}
+
+	// Hyphen ranges, e.g. "0.4.0 - 0.5.0"
+	if len(terms) == 3 && terms[1] == "-" {
+		lower, _, err := parsePartialVersion(terms[0])
+		if err != nil {
+			return nil, err
+		}
+
+		upper, parts, err := parsePartialVersion(terms[2])
+		if err != nil {
+			return nil, err
+		}
+
+		if parts == 3 {
+			return []comparator{{">=", lower}, {"<=", upper}}, nil
+		}
+		return []comparator{{">=", lower}, {"<", bump(upper, parts)}}, nil
+	}
+
+	comparators := make([]comparator, 0)
+	for _, term := range terms {
+		m := operatorPattern.FindStringSubmatch(term)
+		op := m[1]
+
+		v, parts, err := parsePartialVersion(m[2])
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case op == "^":
+			// Allow changes that don't modify the left-most non-zero part
+			upper := 0
+			for upper < 2 && v[upper] == 0 && upper < parts-1 {
+				upper++
+			}
+			comparators = append(comparators, comparator{">=", v}, comparator{"<", bump(v, upper+1)})
+		case op == "~=":
+			// Compatible releases, allowing changes to the last part given
+			if parts < 2 {
+				return nil, fmt.Errorf("Invalid compatible release %q, it needs at least two parts", term)
+			}
+			comparators = append(comparators, comparator{">=", v}, comparator{"<", bump(v, parts-1)})
+		case op == "~":
+			if parts == 1 {
+				comparators = append(comparators, comparator{">=", v}, comparator{"<", bump(v, 1)})
+			} else {
+				comparators = append(comparators, comparator{">=", v}, comparator{"<", bump(v, 2)})
+			}
+		case parts < 3 && (op == "" || op == "=" || op == "=="):
+			comparators = append(comparators, comparator{">=", v}, comparator{"<", bump(v, parts)})
+		case parts < 3 && op == ">":
+			comparators = append(comparators, comparator{">=", bump(v, parts)})
+		case parts < 3 && op == "<=":
+			comparators = append(comparators, comparator{"<", bump(v, parts)})
+		case op == "==":
+			comparators = append(comparators, comparator{"=", v})
+		default:
+			comparators = append(comparators, comparator{op, v})
+		}
+	}
+
+	return comparators, nil
+}
+
+// bump increments the version at the given number of leading parts, e.g.
+// bump(0.4.24, 2) is 0.5.0
+func bump(v version, parts int) version {
+	if parts < 1 {
+		return version{v[0] + 1, 0, 0}
+	}
+
+	var out version
+	copy(out[:parts], v[:parts])
+	out[parts-1]++
+
+	return out
+}
+
+func (c constraint) matches(v version) bool {
+	for _, alternative := range c {
+		ok := true
+		for _, comparator := range alternative {
+			if !comparator.matches(v) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import "testing"
+
+func TestConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"0.4.24", "0.4.24", true},
+		{"0.4.24", "0.4.25", false},
+		{"=0.4.24", "0.4.24", true},
+		{"^0.4.24", "0.4.24", true},
+		{"^0.4.24", "0.4.26", true},
+		{"^0.4.24", "0.4.23", false},
+		{"^0.4.24", "0.5.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"~0.4.24", "0.4.99", true},
+		{"~0.4.24", "0.5.0", false},
+		{"~0", "0.9.0", true},
+		{"~0", "1.0.0", false},
+		{">=0.4.22 <0.6.0", "0.5.17", true},
+		{">=0.4.22 <0.6.0", "0.6.0", false},
+		{">=0.4.22 <0.6.0", "0.4.21", false},
+		{">= 0.4.22 < 0.6.0", "0.5.0", true},
+		{">0.4", "0.4.99", false},
+		{">0.4", "0.5.0", true},
+		{"<=0.5", "0.5.17", true},
+		{"<=0.5", "0.6.0", false},
+		{"0.5", "0.5.3", true},
+		{"0.5.x", "0.6.0", false},
+		{"0.4.0 - 0.5.0", "0.5.0", true},
+		{"0.4.0 - 0.5.0", "0.5.1", false},
+		{"0.4.0 - 0.5", "0.5.17", true},
+		{"0.4.0 - 0.5", "0.6.0", false},
+		{"^0.4.24 || ^0.5.0", "0.5.3", true},
+		{"^0.4.24 || ^0.5.0", "0.6.0", false},
+
+		// PEP 440, as used by vyper
+		{"~=0.4.0", "0.4.0", true},
+		{"~=0.4.0", "0.4.9", true},
+		{"~=0.4.0", "0.5.0", false},
+		{"~=0.4.0", "0.3.10", false},
+		{"~=0.4", "0.9.0", true},
+		{"~=0.4", "1.0.0", false},
+		{"==0.3.10", "0.3.10", true},
+		{"==0.3.10", "0.3.1", false},
+		{"== 0.3.10", "0.3.10", true},
+		{"==0.3.*", "0.3.7", true},
+		{"==0.3.*", "0.4.0", false},
+		{">=0.3.9,<0.4.0", "0.3.10", true},
+		{">=0.3.9, <0.4.0", "0.4.0", false},
+	}
+
+	for _, test := range tests {
+		c, err := parseConstraint(test.constraint)
+		if err != nil {
+			t.Errorf("parseConstraint(%q): %v", test.constraint, err)
+			continue
+		}
+
+		v, err := parseVersion(test.version)
+		if err != nil {
+			t.Fatalf("parseVersion(%q): %v", test.version, err)
+		}
+
+		if got := c.matches(v); got != test.want {
+			t.Errorf("%q matches %s = %v, want %v", test.constraint, test.version, got, test.want)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    version
+		err     bool
+	}{
+		{"0.4.24", version{0, 4, 24}, false},
+		{"v0.8.19", version{0, 8, 19}, false},
+		{"0.8.19+commit.7dd6d404", version{0, 8, 19}, false},
+		{"0.8", version{}, true},
+		{"latest", version{}, true},
+	}
+
+	for _, test := range tests {
+		got, err := parseVersion(test.version)
+		if (err != nil) != test.err {
+			t.Errorf("parseVersion(%q) error = %v, want error %v", test.version, err, test.err)
+			continue
+		}
+
+		if err == nil && got != test.want {
+			t.Errorf("parseVersion(%q) = %s, want %s", test.version, got, test.want)
+		}
+	}
+}
+
+func TestVyperPragmaConstraint(t *testing.T) {
+	tests := []struct {
+		source  string
+		version string
+		want    bool
+	}{
+		{"# pragma version ~=0.4.0\n", "0.4.1", true},
+		{"# pragma version ~=0.4.0\n", "0.5.0", false},
+		{"# pragma version ==0.3.10\n", "0.3.10", true},
+		{"# @version ^0.3.0\n", "0.3.10", true},
+		{"# @version >=0.3.9,<0.4.0\n", "0.4.0", false},
+	}
+
+	for _, test := range tests {
+		pragmas := vyperBackend{}.parsePragmas([]byte(test.source))
+		if len(pragmas) != 1 {
+			t.Fatalf("parsePragmas(%q) = %q, want one pragma", test.source, pragmas)
+		}
+
+		c, err := parseConstraint(pragmas[0])
+		if err != nil {
+			t.Errorf("%q: %v", test.source, err)
+			continue
+		}
+
+		if got := c.matches(mustParseVersion(t, test.version)); got != test.want {
+			t.Errorf("%q matches %s = %v, want %v", test.source, test.version, got, test.want)
+		}
+	}
+
+	if _, err := parseConstraint("~=0"); err == nil {
+		t.Error(`parseConstraint("~=0") succeeded, a compatible release needs two parts`)
+	}
+}
+
+func mustParseVersion(t *testing.T, s string) version {
+	t.Helper()
+
+	v, err := parseVersion(s)
+	if err != nil {
+		t.Fatalf("parseVersion(%q): %v", s, err)
+	}
+
+	return v
+}
@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// contractOutputs is what solc is asked to produce for every contract
+var contractOutputs = []string{
+	"abi",
+	"ast",
+	"metadata",
+	"storageLayout",
+	"userdoc",
+	"devdoc",
+	"evm.bytecode.object",
+	"evm.bytecode.sourceMap",
+	"evm.bytecode.linkReferences",
+	"evm.deployedBytecode.object",
+	"evm.deployedBytecode.sourceMap",
+	"evm.deployedBytecode.linkReferences",
+}
+
+var evmVersions = []string{
+	"homestead",
+	"tangerineWhistle",
+	"spuriousDragon",
+	"byzantium",
+	"constantinople",
+	"petersburg",
+	"istanbul",
+	"berlin",
+	"london",
+	"paris",
+	"shanghai",
+	"cancun",
+	"prague",
+}
+
+var bytecodeHashes = []string{"ipfs", "bzzr1", "none"}
+
+// optimizerDetails are the keys of solc's optimizer details, at any depth.
+// viper lowercases the keys of maps, so they're spelled as solc expects again
+// before being passed on
+var optimizerDetails = []string{
+	"peephole",
+	"inliner",
+	"jumpdestRemover",
+	"orderLiterals",
+	"deduplicate",
+	"cse",
+	"constantOptimizer",
+	"simpleCounterForLoopUncheckedIncrement",
+	"yul",
+	"yulDetails",
+	"stackAllocation",
+	"optimizerSteps",
+}
+
+var contractPattern = regexp.MustCompile(`\b(?:contract|library|interface)\s+([A-Za-z_$][A-Za-z0-9_$]*)`)
+
+// solcSettings is the settings object of the solc standard-json input
+type solcSettings struct {
+	Optimizer       solcOptimizer                  `json:"optimizer"`
+	EVMVersion      string                         `json:"evmVersion,omitempty"`
+	ViaIR           bool                           `json:"viaIR,omitempty"`
+	Metadata        *solcMetadata                  `json:"metadata,omitempty"`
+	Remappings      []string                       `json:"remappings,omitempty"`
+	OutputSelection map[string]map[string][]string `json:"outputSelection,omitempty"`
+}
+
+type solcOptimizer struct {
+	Enabled bool                   `json:"enabled"`
+	Runs    int                    `json:"runs"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type solcMetadata struct {
+	BytecodeHash      string `json:"bytecodeHash,omitempty"`
+	UseLiteralContent bool   `json:"useLiteralContent,omitempty"`
+}
+
+// settingsConfig is a set of compiler settings in wb.yaml, any of which may be
+// left unset to inherit the default or the settings being overridden
+type settingsConfig struct {
+	Optimizer  optimizerConfig `mapstructure:"optimizer"`
+	EVMVersion string          `mapstructure:"evm_version"`
+	ViaIR      *bool           `mapstructure:"via_ir"`
+	Metadata   metadataConfig  `mapstructure:"metadata"`
+}
+
+type optimizerConfig struct {
+	Enabled *bool                  `mapstructure:"enabled"`
+	Runs    *int                   `mapstructure:"runs"`
+	Details map[string]interface{} `mapstructure:"details"`
+}
+
+type metadataConfig struct {
+	BytecodeHash      string `mapstructure:"bytecode_hash"`
+	UseLiteralContent *bool  `mapstructure:"use_literal_content"`
+}
+
+// overrideConfig applies settings to the sources matching any of Files (path
+// globs relative to the contracts directory) and to the contracts named in
+// Contracts
+type overrideConfig struct {
+	Files          []string `mapstructure:"files"`
+	Contracts      []string `mapstructure:"contracts"`
+	settingsConfig `mapstructure:",squash"`
+}
+
+// compilerConfig is the `compiler:` section of wb.yaml
+type compilerConfig struct {
+	settingsConfig `mapstructure:",squash"`
+	Overrides      []overrideConfig `mapstructure:"overrides"`
+
+	// Remappings are passed to solc, and used along with IncludePaths to
+	// find the files imported from outside the contracts directory
+	Remappings   []string `mapstructure:"remappings"`
+	IncludePaths []string `mapstructure:"include_paths"`
+
+	// WarningsAsErrors fails the build on warnings, other than those whose
+	// solc error codes are listed in SuppressedCodes, which aren't reported
+	WarningsAsErrors bool     `mapstructure:"warnings_as_errors"`
+	SuppressedCodes  []string `mapstructure:"suppressed_codes"`
+}
+
+func loadCompilerConfig() (*compilerConfig, error) {
+	config := &compilerConfig{}
+	if err := viper.UnmarshalKey("compiler", config); err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *compilerConfig) validate() error {
+	if err := c.settingsConfig.validate("compiler"); err != nil {
+		return err
+	}
+
+	if _, err := parseRemappings(c.Remappings); err != nil {
+		return err
+	}
+
+	for _, code := range c.SuppressedCodes {
+		if _, err := strconv.Atoi(code); err != nil {
+			return fmt.Errorf("compiler.suppressed_codes: %q is not a solc error code", code)
+		}
+	}
+
+	for i, override := range c.Overrides {
+		prefix := fmt.Sprintf("compiler.overrides[%d]", i)
+		if len(override.Files) == 0 && len(override.Contracts) == 0 {
+			return fmt.Errorf("%s: must specify files or contracts", prefix)
+		}
+
+		for _, pattern := range override.Files {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s: invalid file pattern %q", prefix, pattern)
+			}
+		}
+
+		if err := override.settingsConfig.validate(prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *settingsConfig) validate(prefix string) error {
+	if c.Optimizer.Runs != nil && (*c.Optimizer.Runs < 0 || int64(*c.Optimizer.Runs) > 1<<32-1) {
+		return fmt.Errorf("%s.optimizer.runs: must be between 0 and 4294967295", prefix)
+	}
+
+	if c.EVMVersion != "" && !contains(evmVersions, c.EVMVersion) {
+		return fmt.Errorf("%s.evm_version: unknown EVM version %q", prefix, c.EVMVersion)
+	}
+
+	if c.Metadata.BytecodeHash != "" && !contains(bytecodeHashes, c.Metadata.BytecodeHash) {
+		return fmt.Errorf("%s.metadata.bytecode_hash: must be one of %v", prefix, bytecodeHashes)
+	}
+
+	return nil
+}
+
+// apply returns a copy of the given settings with the configured values set
+func (c *settingsConfig) apply(settings solcSettings) solcSettings {
+	if c.Optimizer.Enabled != nil {
+		settings.Optimizer.Enabled = *c.Optimizer.Enabled
+	}
+	if c.Optimizer.Runs != nil {
+		settings.Optimizer.Runs = *c.Optimizer.Runs
+	}
+	if c.Optimizer.Details != nil {
+		settings.Optimizer.Details = canonicalKeys(normalizeMap(c.Optimizer.Details), optimizerDetails)
+	}
+	if c.EVMVersion != "" {
+		settings.EVMVersion = c.EVMVersion
+	}
+	if c.ViaIR != nil {
+		settings.ViaIR = *c.ViaIR
+	}
+	if c.Metadata.BytecodeHash != "" || c.Metadata.UseLiteralContent != nil {
+		metadata := solcMetadata{}
+		if settings.Metadata != nil {
+			metadata = *settings.Metadata
+		}
+		if c.Metadata.BytecodeHash != "" {
+			metadata.BytecodeHash = c.Metadata.BytecodeHash
+		}
+		if c.Metadata.UseLiteralContent != nil {
+			metadata.UseLiteralContent = *c.Metadata.UseLiteralContent
+		}
+		settings.Metadata = &metadata
+	}
+
+	return settings
+}
+
+func defaultSettings() solcSettings {
+	return solcSettings{
+		Optimizer: solcOptimizer{
+			Enabled: true,
+			Runs:    200,
+		},
+	}
+}
+
+// withOutputs returns a copy of the settings selecting every contract in the
+// given sources, or only the named contract if one is given
+func (s solcSettings) withOutputs(sources []string, contract string) solcSettings {
+	if contract == "" {
+		contract = "*"
+	}
+
+	s.OutputSelection = make(map[string]map[string][]string)
+	for _, source := range sources {
+		s.OutputSelection[source] = map[string][]string{contract: contractOutputs}
+	}
+
+	return s
+}
+
+func (s solcSettings) hash() string {
+	data, _ := json.Marshal(s)
+	return hashBytes(data)
+}
+
+// settingsPlan is the effective settings of every source, and of any contracts
+// with settings of their own
+type settingsPlan struct {
+	Sources   map[string]solcSettings
+	Contracts map[string]map[string]solcSettings
+}
+
+func planSettings(sources sourceSet, config *compilerConfig) *settingsPlan {
+	plan := &settingsPlan{
+		Sources:   make(map[string]solcSettings),
+		Contracts: make(map[string]map[string]solcSettings),
+	}
+
+	base := config.settingsConfig.apply(defaultSettings())
+	base.Remappings = config.Remappings
+	for _, name := range sources.names() {
+		settings := base
+		for _, override := range config.Overrides {
+			if override.matchesFile(name) {
+				settings = override.apply(settings)
+			}
+		}
+		plan.Sources[name] = settings
+	}
+
+	// Contract overrides are layered on top of the settings of the file
+	// declaring the contract
+	for _, name := range sources.names() {
+		for _, contract := range sources[name].Backend.declaredContracts(name, sources[name].Content) {
+			settings, overridden := plan.Sources[name], false
+			for _, override := range config.Overrides {
+				if contains(override.Contracts, contract) {
+					settings, overridden = override.apply(settings), true
+				}
+			}
+
+			if overridden {
+				if plan.Contracts[name] == nil {
+					plan.Contracts[name] = make(map[string]solcSettings)
+				}
+				plan.Contracts[name][contract] = settings
+			}
+		}
+	}
+
+	return plan
+}
+
+func (o *overrideConfig) matchesFile(name string) bool {
+	for _, pattern := range o.Files {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hash identifies every setting a source is compiled with
+func (p *settingsPlan) hash(name string) string {
+	hashes := []byte(p.Sources[name].hash())
+	for _, contract := range sortedSettingsNames(p.Contracts[name]) {
+		hashes = append(hashes, contract+p.Contracts[name][contract].hash()...)
+	}
+
+	return hashBytes(hashes)
+}
+
+// compileJob is a single compiler invocation
+type compileJob struct {
+	Compiler *installedCompiler
+	Settings solcSettings
+	Sources  []string
+
+	// Contract, if set, limits the job to a single contract with its own
+	// settings
+	Contract string
+}
+
+// jobs splits the given sources into compiler invocations, one for each distinct
+// compiler and settings and group of sources that don't import each other,
+// followed by one for each contract with overridden settings so its artifacts
+// replace those from the rest of its file
+func (p *settingsPlan) jobs(sources sourceSet, names []string, compilers map[string]*installedCompiler) []*compileJob {
+	groups := make([]*compileJob, 0)
+	byKey := make(map[string]*compileJob)
+	for _, name := range names {
+		key := compilers[name].String() + p.Sources[name].hash()
+		job, ok := byKey[key]
+		if !ok {
+			job = &compileJob{Compiler: compilers[name], Settings: p.Sources[name]}
+			byKey[key] = job
+			groups = append(groups, job)
+		}
+		job.Sources = append(job.Sources, name)
+	}
+
+	jobs := make([]*compileJob, 0, len(groups))
+	for _, group := range groups {
+		for _, component := range sources.components(group.Sources) {
+			jobs = append(jobs, &compileJob{Compiler: group.Compiler, Settings: group.Settings, Sources: component})
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i].Compiler, jobs[j].Compiler
+		if a.Backend.Name() != b.Backend.Name() {
+			return a.Backend.Name() < b.Backend.Name()
+		}
+		return a.Version.compare(b.Version) < 0
+	})
+
+	for _, name := range names {
+		for _, contract := range sortedSettingsNames(p.Contracts[name]) {
+			jobs = append(jobs, &compileJob{
+				Compiler: compilers[name],
+				Settings: p.Contracts[name][contract],
+				Sources:  []string{name},
+				Contract: contract,
+			})
+		}
+	}
+
+	for _, job := range jobs {
+		job.Settings = job.Settings.withOutputs(job.Sources, job.Contract)
+
+		// Imported library files are selected too, in case the job's
+		// contracts need one of their libraries linked
+		for _, name := range sources.closure(job.Sources) {
+			if sources[name].Library {
+				job.Settings.OutputSelection[name] = map[string][]string{"*": contractOutputs}
+			}
+		}
+	}
+
+	return jobs
+}
+
+// owns reports whether a contract in the solc output belongs to this job
+func (j *compileJob) owns(source, contract string) bool {
+	if j.Contract != "" && j.Contract != contract {
+		return false
+	}
+
+	return contains(j.Sources, source)
+}
+
+func (j *compileJob) String() string {
+	if j.Contract != "" {
+		return fmt.Sprintf("contract %s with %s", j.Contract, j.Compiler)
+	}
+
+	return fmt.Sprintf("%d source files with %s", len(j.Sources), j.Compiler)
+}
+
+// showSettings prints the effective settings of every source and overridden
+// contract
+func showSettings(plan *settingsPlan) error {
+	groups := make(map[string][]string)
+	keys := make([]string, 0)
+	for _, name := range sortedSettingsNames(plan.Sources) {
+		key := plan.Sources[name].hash()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+
+	for _, key := range keys {
+		if err := printSettings(strings.Join(groups[key], ", "), plan.Sources[groups[key][0]]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedSettingsNames(plan.Sources) {
+		for _, contract := range sortedSettingsNames(plan.Contracts[name]) {
+			if err := printSettings(fmt.Sprintf("%s:%s", name, contract), plan.Contracts[name][contract]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func printSettings(title string, settings solcSettings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n%s\n\n", title, data)
+	return nil
+}
+
+// declaredContracts returns the names of the contracts, libraries and
+// interfaces declared in a source
+func declaredContracts(content []byte) []string {
+	names := make([]string, 0)
+	for _, m := range contractPattern.FindAllStringSubmatch(stripComments(string(content)), -1) {
+		names = append(names, m[1])
+	}
+
+	return names
+}
+
+func sortedSettingsNames(m map[string]solcSettings) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// normalizeMap converts the map[interface{}]interface{} values produced by the
+// yaml parser so they can be marshalled as json
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[key] = normalizeValue(value)
+	}
+
+	return out
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeMap(v)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = value
+		}
+		return normalizeMap(m)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = normalizeValue(value)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// canonicalKeys returns a copy of a normalized map, and the maps in it, with
+// the keys that match one of names ignoring case spelled as in names
+func canonicalKeys(m map[string]interface{}, names []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				key = name
+				break
+			}
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = canonicalKeys(nested, names)
+		}
+		out[key] = value
+	}
+
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
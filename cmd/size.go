@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/project"
+)
+
+const (
+	sizesFilename = ".sizes.json"
+
+	// runtimeSizeLimit is the largest deployed contract allowed by EIP-170,
+	// and initCodeSizeLimit the largest creation code allowed by EIP-3860
+	runtimeSizeLimit  = 24576
+	initCodeSizeLimit = 2 * runtimeSizeLimit
+
+	defaultSizeWarnPercent = 90
+)
+
+var sizeActions = []string{"warn", "fail"}
+
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Show contract sizes against the EIP-170 and EIP-3860 limits",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunInRoot(func() error {
+			config, err := loadSizeConfig()
+			if err != nil {
+				return err
+			}
+
+			record := loadSizeRecord()
+			if len(record.Current) == 0 {
+				return errors.New("No contract sizes recorded, build the contracts with `wb compile`")
+			}
+
+			return printSizes(os.Stdout, record, sortedSizeNames(record.Current), config)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(sizeCmd)
+}
+
+// sizeConfig is the `size:` section of wb.yaml
+type sizeConfig struct {
+	// WarnPercent is how close to a limit, as a percentage of it, a contract
+	// can get before a warning is printed
+	WarnPercent int `mapstructure:"warn_percent"`
+
+	// OverLimit is whether a contract over a limit is a warning or fails
+	// the build
+	OverLimit string `mapstructure:"over_limit"`
+}
+
+func loadSizeConfig() (*sizeConfig, error) {
+	config := &sizeConfig{}
+	if err := viper.UnmarshalKey("size", config); err != nil {
+		return nil, err
+	}
+
+	if config.WarnPercent == 0 {
+		config.WarnPercent = defaultSizeWarnPercent
+	}
+	if config.OverLimit == "" {
+		config.OverLimit = "warn"
+	}
+
+	if config.WarnPercent < 0 || config.WarnPercent > 100 {
+		return nil, errors.New("size.warn_percent: must be between 0 and 100")
+	}
+
+	if !contains(sizeActions, config.OverLimit) {
+		return nil, fmt.Errorf("size.over_limit: must be one of %s", strings.Join(sizeActions, ", "))
+	}
+
+	return config, nil
+}
+
+// contractSize is the size in bytes of a contract's deployed code and of the
+// code that creates it
+type contractSize struct {
+	Runtime int `json:"runtime"`
+	Init    int `json:"init"`
+}
+
+func artifactSize(a *artifact) contractSize {
+	// Library placeholders are the same length as the addresses replacing
+	// them, so unlinked bytecode has its final size
+	return contractSize{
+		Runtime: len(a.DeployedBytecode) / 2,
+		Init:    len(a.Bytecode) / 2,
+	}
+}
+
+// sizeRecord is the size of every contract as of the last build it changed
+// in, and before that, so builds can be compared
+type sizeRecord struct {
+	Current  map[string]contractSize `json:"current"`
+	Previous map[string]contractSize `json:"previous"`
+}
+
+func sizeRecordPath() string {
+	return filepath.Join(project.BuildDirectory, sizesFilename)
+}
+
+func loadSizeRecord() *sizeRecord {
+	record := &sizeRecord{}
+
+	data, err := ioutil.ReadFile(sizeRecordPath())
+	if err == nil {
+		json.Unmarshal(data, record)
+	}
+
+	if record.Current == nil {
+		record.Current = make(map[string]contractSize)
+	}
+	if record.Previous == nil {
+		record.Previous = make(map[string]contractSize)
+	}
+
+	return record
+}
+
+func (r *sizeRecord) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(sizeRecordPath(), data, 0644)
+}
+
+// checkSizes records the sizes of freshly built contracts, prints them, and
+// warns about or fails on any that are too big to deploy
+func checkSizes(w io.Writer, artifacts []*artifact) error {
+	config, err := loadSizeConfig()
+	if err != nil {
+		return err
+	}
+
+	record := loadSizeRecord()
+	built := make(map[string]bool)
+	for _, a := range artifacts {
+		size := artifactSize(a)
+
+		// Interfaces and abstract contracts have no code to deploy
+		if size.Runtime == 0 && size.Init == 0 {
+			continue
+		}
+
+		if current, ok := record.Current[a.ContractName]; ok && current != size {
+			record.Previous[a.ContractName] = current
+		}
+		record.Current[a.ContractName] = size
+		built[a.ContractName] = true
+	}
+
+	if err := record.save(); err != nil {
+		return err
+	}
+
+	if len(built) == 0 {
+		return nil
+	}
+
+	names := sortedKeys(built)
+	if err := printSizes(w, record, names, config); err != nil {
+		return err
+	}
+
+	over := make([]string, 0)
+	for _, name := range names {
+		size := record.Current[name]
+		switch sizeStatus(size, config) {
+		case "over limit":
+			over = append(over, name)
+			fmt.Fprintf(w, "Warning: %s is over the size limit, at %d bytes deployed and %d bytes of init code\n", name, size.Runtime, size.Init)
+		case "near limit":
+			fmt.Fprintf(w, "Warning: %s is within %d%% of the size limit\n", name, 100-config.WarnPercent)
+		}
+	}
+
+	if len(over) > 0 && config.OverLimit == "fail" {
+		return fmt.Errorf("Contracts over the size limit: %s", strings.Join(over, ", "))
+	}
+
+	return nil
+}
+
+func printSizes(w io.Writer, record *sizeRecord, names []string, config *sizeConfig) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Contract\tRuntime\tChange\tInit\tChange\tOf limit\t")
+
+	for _, name := range names {
+		size := record.Current[name]
+		previous, ok := record.Previous[name]
+
+		runtimeChange, initChange := "", ""
+		if ok {
+			runtimeChange = sizeChange(size.Runtime - previous.Runtime)
+			initChange = sizeChange(size.Init - previous.Init)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%s\t%.1f%%\t%s\n",
+			name, size.Runtime, runtimeChange, size.Init, initChange,
+			100*float64(size.Runtime)/runtimeSizeLimit, strings.ToUpper(sizeStatus(size, config)))
+	}
+
+	return tw.Flush()
+}
+
+// sizeStatus is "over limit" if a contract can't be deployed, "near limit" if
+// it's within the warning threshold of not being deployable, and empty
+// otherwise
+func sizeStatus(size contractSize, config *sizeConfig) string {
+	if size.Runtime > runtimeSizeLimit || size.Init > initCodeSizeLimit {
+		return "over limit"
+	}
+
+	if size.Runtime*100 >= runtimeSizeLimit*config.WarnPercent || size.Init*100 >= initCodeSizeLimit*config.WarnPercent {
+		return "near limit"
+	}
+
+	return ""
+}
+
+func sizeChange(delta int) string {
+	if delta == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%+d", delta)
+}
+
+func sortedSizeNames(sizes map[string]contractSize) []string {
+	names := make(map[string]bool, len(sizes))
+	for name := range sizes {
+		names[name] = true
+	}
+
+	return sortedKeys(names)
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zscole/cli/project"
+)
+
+// sourceFile is a single contract source unit, keyed by its source unit name
+// (the same key used in the solc standard-json input). Files in the contracts
+// directory are named by their path relative to it, imported library files by
+// the import path after remapping.
+type sourceFile struct {
+	Name    string
+	Path    string
+	Content []byte
+	Hash    string
+	Imports []sourceImport
+	Pragmas []string
+
+	// Backend is the compiler for the language of the source
+	Backend compilerBackend
+
+	// Library is set for files imported from outside the contracts
+	// directory, which are compiled as dependencies but produce no artifacts
+	Library bool
+}
+
+// sourceImport is an import statement as written in a source file, and the
+// source unit it resolves to
+type sourceImport struct {
+	Path string
+	Line int
+	Unit string
+}
+
+// remapping rewrites source unit names starting with Prefix to start with
+// Target instead, for imports from units starting with Context
+type remapping struct {
+	Context string
+	Prefix  string
+	Target  string
+}
+
+type sourceSet map[string]*sourceFile
+
+var (
+	importPattern = regexp.MustCompile(`\bimport\s+(?:[^"';]*?\s+from\s+)?["']([^"']+)["']`)
+	pragmaPattern = regexp.MustCompile(`\bpragma\s+solidity\s+([^;]+);`)
+)
+
+func collectSources(dir string) (sourceSet, error) {
+	sources := make(sourceSet)
+	err := filepath.Walk(dir, func(file string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if backendForFile(file) == nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(rel)
+		sources[name] = newSourceFile(name, file, content)
+		return nil
+	})
+
+	return sources, err
+}
+
+// loadSources collects the sources in the contracts directory, then follows
+// their imports through the configured remappings and include paths until
+// every imported file is loaded
+func loadSources(config *compilerConfig) (sourceSet, error) {
+	sources, err := collectSources(project.ContractsDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	remappings, err := parseRemappings(config.Remappings)
+	if err != nil {
+		return nil, err
+	}
+
+	unresolved := make([]string, 0)
+	queue := sources.names()
+	for len(queue) > 0 {
+		source := sources[queue[0]]
+		queue = queue[1:]
+
+		for i, imp := range source.Imports {
+			// Imports the language resolves itself are only followed to
+			// project sources
+			if imp.Unit != "" {
+				continue
+			}
+
+			unit := remap(remappings, source.Name, resolveImport(source.Name, imp.Path))
+			source.Imports[i].Unit = unit
+			if _, ok := sources[unit]; ok {
+				continue
+			}
+
+			file := locateSource(unit, config.IncludePaths)
+			if file == "" {
+				unresolved = append(unresolved, fmt.Sprintf("%s:%d: unresolved import %q", source.Path, imp.Line, imp.Path))
+				continue
+			}
+
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			library := newSourceFile(unit, file, content)
+			library.Library = true
+			sources[unit] = library
+			queue = append(queue, unit)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return nil, errors.New(strings.Join(unresolved, "\n"))
+	}
+
+	return sources, nil
+}
+
+// locateSource finds the file for a source unit name, looking in the contracts
+// directory, the project root and then each include path
+func locateSource(unit string, includePaths []string) string {
+	roots := append([]string{project.ContractsDirectory, "."}, includePaths...)
+	for _, root := range roots {
+		file := filepath.Join(root, filepath.FromSlash(unit))
+		if fi, err := os.Stat(file); err == nil && !fi.IsDir() {
+			return file
+		}
+	}
+
+	return ""
+}
+
+func newSourceFile(name, file string, content []byte) *sourceFile {
+	backend := backendForFile(file)
+	if backend == nil {
+		backend = solcBackend{}
+	}
+
+	return &sourceFile{
+		Name:    name,
+		Path:    file,
+		Content: content,
+		Hash:    hashBytes(content),
+		Imports: backend.parseImports(name, content),
+		Pragmas: backend.parsePragmas(content),
+		Backend: backend,
+	}
+}
+
+// parseImports returns every import in a source, ignoring anything inside
+// comments
+func parseImports(content []byte) []sourceImport {
+	code := stripComments(string(content))
+
+	imports := make([]sourceImport, 0)
+	for _, loc := range importPattern.FindAllStringSubmatchIndex(code, -1) {
+		imports = append(imports, sourceImport{
+			Path: code[loc[2]:loc[3]],
+			Line: strings.Count(code[:loc[0]], "\n") + 1,
+		})
+	}
+
+	return imports
+}
+
+// parsePragmas returns the version constraint of every `pragma solidity` in a
+// source
+func parsePragmas(content []byte) []string {
+	pragmas := make([]string, 0)
+	for _, m := range pragmaPattern.FindAllStringSubmatch(stripComments(string(content)), -1) {
+		pragmas = append(pragmas, strings.TrimSpace(m[1]))
+	}
+
+	return pragmas
+}
+
+// stripComments blanks out solidity comments while preserving line breaks,
+// so offsets into the result still map to the same line in the original
+func stripComments(code string) string {
+	out := []byte(code)
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"' || out[i] == '\'':
+			quote := out[i]
+			for i++; i < len(out) && out[i] != quote && out[i] != '\n'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			for ; i < len(out) && !(out[i] == '*' && i+1 < len(out) && out[i+1] == '/'); i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			if i+1 < len(out) {
+				out[i], out[i+1] = ' ', ' '
+				i++
+			}
+		}
+	}
+
+	return string(out)
+}
+
+// resolveImport maps an import path to the source unit name solc will look
+// for, following solc's rule that only "./" and "../" paths are relative to
+// the importing file
+func resolveImport(importer, imported string) string {
+	if strings.HasPrefix(imported, "./") || strings.HasPrefix(imported, "../") {
+		return path.Join(path.Dir(importer), imported)
+	}
+
+	return path.Clean(imported)
+}
+
+// parseRemappings reads remappings in solc's "[context:]prefix=target" form
+func parseRemappings(specs []string) ([]remapping, error) {
+	remappings := make([]remapping, 0, len(specs))
+	for _, spec := range specs {
+		i := strings.Index(spec, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("compiler.remappings: invalid remapping %q, expected prefix=target", spec)
+		}
+
+		r := remapping{Prefix: spec[:i], Target: spec[i+1:]}
+		if j := strings.Index(r.Prefix, ":"); j >= 0 {
+			r.Context, r.Prefix = r.Prefix[:j], r.Prefix[j+1:]
+		}
+
+		if r.Prefix == "" {
+			return nil, fmt.Errorf("compiler.remappings: invalid remapping %q, prefix is empty", spec)
+		}
+
+		remappings = append(remappings, r)
+	}
+
+	return remappings, nil
+}
+
+// remap applies the remapping with the longest matching context, and then the
+// longest matching prefix, to a source unit name, as solc does
+func remap(remappings []remapping, importer, unit string) string {
+	var best *remapping
+	for i := range remappings {
+		r := &remappings[i]
+		if !strings.HasPrefix(importer, r.Context) || !strings.HasPrefix(unit, r.Prefix) {
+			continue
+		}
+
+		if best == nil || len(r.Context) > len(best.Context) ||
+			(len(r.Context) == len(best.Context) && len(r.Prefix) >= len(best.Prefix)) {
+			best = r
+		}
+	}
+
+	if best == nil {
+		return unit
+	}
+
+	return best.Target + strings.TrimPrefix(unit, best.Prefix)
+}
+
+// dependencies returns the direct imports of a source that are part of the set
+func (s sourceSet) dependencies(name string) []string {
+	deps := make([]string, 0)
+	source, ok := s[name]
+	if !ok {
+		return deps
+	}
+
+	for _, imp := range source.Imports {
+		if _, ok := s[imp.Unit]; ok {
+			deps = append(deps, imp.Unit)
+		}
+	}
+
+	return deps
+}
+
+// closure returns the given sources along with everything they import,
+// directly or transitively, in sorted order
+func (s sourceSet) closure(names []string) []string {
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+
+		for _, dep := range s.dependencies(name) {
+			visit(dep)
+		}
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return sortedKeys(seen)
+}
+
+// components splits sources into groups that share no files, directly or
+// through their imports, so each group can be compiled on its own
+func (s sourceSet) components(names []string) [][]string {
+	parent := make(map[string]string)
+	var find func(name string) string
+	find = func(name string) string {
+		if p, ok := parent[name]; ok && p != name {
+			root := find(p)
+			parent[name] = root
+			return root
+		}
+		parent[name] = name
+		return name
+	}
+
+	for _, name := range names {
+		for _, dep := range s.closure([]string{name}) {
+			if a, b := find(name), find(dep); a != b {
+				parent[a] = b
+			}
+		}
+	}
+
+	groups := make(map[string][]string)
+	roots := make([]string, 0)
+	for _, name := range names {
+		root := find(name)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], name)
+	}
+
+	components := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		components = append(components, groups[root])
+	}
+
+	return components
+}
+
+// cycles returns every import cycle among the sources, each as the path of
+// source units from the first back to itself. Cycles are listed once, starting
+// from their smallest unit
+func (s sourceSet) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	stack := make([]string, 0)
+	seen := make(map[string]bool)
+	cycles := make([][]string, 0)
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		for _, dep := range s.dependencies(name) {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				// The stack from dep back up to here is the cycle
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := rotateCycle(stack[start:])
+
+				key := strings.Join(cycle, "\x00")
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, append(cycle, cycle[0]))
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return cycles
+}
+
+// rotateCycle returns a copy of a cycle starting from its smallest unit
+func rotateCycle(cycle []string) []string {
+	first := 0
+	for i, name := range cycle {
+		if name < cycle[first] {
+			first = i
+		}
+	}
+
+	return append(append([]string{}, cycle[first:]...), cycle[:first]...)
+}
+
+// unitHash identifies a source together with everything it imports, so a
+// change to any file in the compilation unit changes the hash
+func (s sourceSet) unitHash(name string) string {
+	h := sha256.New()
+	for _, dep := range s.closure([]string{name}) {
+		h.Write([]byte(dep))
+		h.Write([]byte(s[dep].Hash))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// names returns the project's own sources, excluding imported library files
+func (s sourceSet) names() []string {
+	names := make([]string, 0, len(s))
+	for name, source := range s {
+		if !source.Library {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func hashBytes(data ...[]byte) string {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveImport(t *testing.T) {
+	tests := []struct {
+		importer string
+		imported string
+		want     string
+	}{
+		{"Token.sol", "./Math.sol", "Math.sol"},
+		{"token/Token.sol", "./Math.sol", "token/Math.sol"},
+		{"token/Token.sol", "../lib/Math.sol", "lib/Math.sol"},
+		{"token/erc20/Token.sol", "../../Owned.sol", "Owned.sol"},
+		{"token/Token.sol", "lib/Math.sol", "lib/Math.sol"},
+		{"token/Token.sol", "@openzeppelin/contracts/token/ERC20/ERC20.sol", "@openzeppelin/contracts/token/ERC20/ERC20.sol"},
+		{"token/Token.sol", "lib/./utils/../Math.sol", "lib/Math.sol"},
+		{"token/Token.sol", ".hidden/Math.sol", ".hidden/Math.sol"},
+	}
+
+	for _, test := range tests {
+		if got := resolveImport(test.importer, test.imported); got != test.want {
+			t.Errorf("resolveImport(%q, %q) = %q, want %q", test.importer, test.imported, got, test.want)
+		}
+	}
+}
+
+func TestRemap(t *testing.T) {
+	remappings, err := parseRemappings([]string{
+		"@openzeppelin/=lib/openzeppelin-contracts/",
+		"@openzeppelin/contracts/=lib/oz/contracts/",
+		"legacy/:@openzeppelin/=lib/openzeppelin-v2/",
+		"ds-test/=lib/ds-test/src/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		importer string
+		unit     string
+		want     string
+	}{
+		{"Token.sol", "@openzeppelin/utils/Address.sol", "lib/openzeppelin-contracts/utils/Address.sol"},
+		{"Token.sol", "@openzeppelin/contracts/token/ERC20.sol", "lib/oz/contracts/token/ERC20.sol"},
+		{"legacy/Token.sol", "@openzeppelin/contracts/token/ERC20.sol", "lib/openzeppelin-v2/contracts/token/ERC20.sol"},
+		{"legacy/Token.sol", "ds-test/test.sol", "lib/ds-test/src/test.sol"},
+		{"Token.sol", "lib/Math.sol", "lib/Math.sol"},
+	}
+
+	for _, test := range tests {
+		if got := remap(remappings, test.importer, test.unit); got != test.want {
+			t.Errorf("remap(%q, %q) = %q, want %q", test.importer, test.unit, got, test.want)
+		}
+	}
+}
+
+func TestParseRemappings(t *testing.T) {
+	tests := []struct {
+		spec string
+		want remapping
+		err  bool
+	}{
+		{spec: "@oz/=lib/oz/", want: remapping{Prefix: "@oz/", Target: "lib/oz/"}},
+		{spec: "legacy/:@oz/=lib/oz-v2/", want: remapping{Context: "legacy/", Prefix: "@oz/", Target: "lib/oz-v2/"}},
+		{spec: "@oz/=", want: remapping{Prefix: "@oz/"}},
+		{spec: "lib/oz/", err: true},
+		{spec: "=lib/oz/", err: true},
+		{spec: "legacy/:=lib/oz/", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseRemappings([]string{test.spec})
+		if (err != nil) != test.err {
+			t.Errorf("parseRemappings(%q) error = %v, want error %v", test.spec, err, test.err)
+			continue
+		}
+
+		if err == nil && got[0] != test.want {
+			t.Errorf("parseRemappings(%q) = %+v, want %+v", test.spec, got[0], test.want)
+		}
+	}
+}
+
+func TestSourceCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents map[string]string
+		want     [][]string
+	}{
+		{
+			name: "two files",
+			contents: map[string]string{
+				"Token.sol": `import "./Vault.sol"; contract Token {}`,
+				"Vault.sol": `import "./Token.sol"; contract Vault {}`,
+			},
+			want: [][]string{{"Token.sol", "Vault.sol", "Token.sol"}},
+		},
+		{
+			name: "self import",
+			contents: map[string]string{
+				"Token.sol": `import "./Token.sol"; contract Token {}`,
+			},
+			want: [][]string{{"Token.sol", "Token.sol"}},
+		},
+		{
+			name: "diamond",
+			contents: map[string]string{
+				"Token.sol":         `import "./lib/Left.sol"; import "./lib/Right.sol"; contract Token {}`,
+				"lib/Left.sol":      `import "./Math.sol"; library Left {}`,
+				"lib/Right.sol":     `import "./Math.sol"; library Right {}`,
+				"lib/Math.sol":      `library Math {}`,
+				"lib/Unrelated.sol": `import "./Math.sol"; library Unrelated {}`,
+			},
+			want: [][]string{},
+		},
+		{
+			name: "cycle behind a diamond, reached twice",
+			contents: map[string]string{
+				"Token.sol":     `import "./lib/Left.sol"; import "./lib/Right.sol"; contract Token {}`,
+				"lib/Left.sol":  `import "./Math.sol"; library Left {}`,
+				"lib/Right.sol": `import "./Math.sol"; library Right {}`,
+				"lib/Math.sol":  `import "./Util.sol"; library Math {}`,
+				"lib/Util.sol":  `import "./Math.sol"; library Util {}`,
+			},
+			want: [][]string{{"lib/Math.sol", "lib/Util.sol", "lib/Math.sol"}},
+		},
+	}
+
+	for _, test := range tests {
+		got := testSources(test.contents).cycles()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: cycles() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
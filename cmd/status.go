@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/deployments"
+	"github.com/zscole/cli/project"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the migrations run and contracts deployed on a network",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("default_network", cmd.Flags().Lookup("network"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunInRoot(func() error {
+			return showStatus(viper.GetString("default_network"))
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringP("network", "n", "dev", "network to show the status of")
+}
+
+// perigordMigrations is the contract perigord records migrations in. Its
+// binding comes with perigord, so the project has no artifact to compare
+const perigordMigrations = "Migrations"
+
+// migrationFile is a migration in the project's migrations directory
+type migrationFile struct {
+	Number int
+	Name   string
+}
+
+func showStatus(network string) error {
+	registry, err := deployments.Load(".", network)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := migrationFiles()
+	if err != nil {
+		return err
+	}
+
+	// Libraries are linked at the addresses the network's config or registry
+	// has for them, without deploying any
+	config, err := loadNetworkConfig(network)
+	if err != nil {
+		config = &networkConfig{Name: network}
+	}
+	l := &linker{network: config, registry: registry}
+
+	fmt.Println("Network:", network)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	ran := make(map[int]deployments.Migration)
+	for _, m := range registry.Migrations {
+		ran[m.Number] = m
+	}
+
+	fmt.Fprintln(w, "\nMigrations")
+	if len(migrations) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, m := range migrations {
+		state := "pending"
+		if r, ok := ran[m.Number]; ok {
+			state = "ran " + r.Timestamp.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\n", m.Number, m.Name, state)
+	}
+
+	fmt.Fprintln(w, "\nContracts")
+	if len(registry.Contracts) == 0 {
+		fmt.Fprintln(w, "  none deployed")
+	}
+	for _, name := range sortedContractNames(registry.Contracts) {
+		contract := registry.Contracts[name]
+		fmt.Fprintf(w, "  %s\t%s\tblock %d\tmigration %d\t%s\n", name, contract.Address, contract.Block, contract.Migration, bytecodeState(l, name, contract))
+	}
+
+	if len(registry.Libraries) > 0 {
+		fmt.Fprintln(w, "\nLibraries")
+		names := make([]string, 0, len(registry.Libraries))
+		for name := range registry.Libraries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s\t%s\n", name, registry.Libraries[name].Address)
+		}
+	}
+
+	return w.Flush()
+}
+
+// migrationFiles returns the project's migrations in the order they run,
+// numbered by their filename prefix as `wb add migration` names them
+func migrationFiles() ([]migrationFile, error) {
+	matches, err := filepath.Glob(filepath.Join(project.MigrationsDirectory, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migrationFile, 0, len(matches))
+	for _, match := range matches {
+		base := strings.TrimSuffix(filepath.Base(match), ".go")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		number, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		migrations = append(migrations, migrationFile{Number: number, Name: parts[1]})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Number < migrations[j].Number
+	})
+
+	return migrations, nil
+}
+
+// bytecodeState compares a deployed contract with its current build artifact,
+// linked for the network in memory if it needs linking, since the linked
+// bytecode on disk may be from an older build
+func bytecodeState(l *linker, name string, contract deployments.Contract) string {
+	a, err := loadArtifact(name)
+	if err != nil && name == perigordMigrations {
+		return "comes with perigord"
+	}
+	if err != nil {
+		return "no build artifact"
+	}
+
+	bin, _, err := l.linkArtifact(a)
+	if err != nil {
+		return "libraries not linkable"
+	}
+
+	if deployments.BytecodeHash(bin) != contract.BytecodeHash {
+		return "changed since deployment"
+	}
+
+	return "up to date"
+}
+
+func sortedContractNames(contracts map[string]deployments.Contract) []string {
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
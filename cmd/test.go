@@ -1,13 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
+	"os"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zscole/cli/simulated"
 )
 
 var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Run go and solidity tests",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("watch", cmd.Flags().Lookup("watch"))
+		viper.BindPFlag("test_network", cmd.Flags().Lookup("network"))
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := RunInRoot(func() error { return setTestNetwork(viper.GetString("test_network")) }); err != nil {
+			Fatal(err)
+		}
+
+		if viper.GetBool("watch") {
+			if err := RunInRoot(func() error { return watch(true) }); err != nil {
+				Fatal(err)
+			}
+			return
+		}
+
 		if err := runStub("test"); err != nil {
 			Fatal(err)
 		}
@@ -16,4 +37,37 @@ var testCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().Bool("watch", false, "recompile and rerun tests whenever contracts, migrations or tests change")
+	testCmd.Flags().StringP("network", "n", "dev", "network to run tests on")
+	viper.SetDefault("test_network", "dev")
+}
+
+// setTestNetwork passes the configuration of a simulated test network on to
+// the tests, which start the chain themselves
+func setTestNetwork(name string) error {
+	if !viper.IsSet("networks." + name) {
+		return nil
+	}
+
+	config, err := loadNetworkConfig(name)
+	if err != nil {
+		return err
+	}
+
+	if !config.simulated() {
+		return nil
+	}
+
+	chain, err := config.simulatedConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(chain)
+	if err != nil {
+		return err
+	}
+
+	return os.Setenv(simulated.ConfigEnv, string(data))
 }
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zscole/cli/project"
+)
+
+var upgradeCheckCmd = &cobra.Command{
+	Use:   "upgrade-check CONTRACT",
+	Short: "Check a contract's storage layout is compatible with an earlier version",
+	Long: `Compares the storage layout of a contract with an earlier version of it, and
+fails if any variable was removed, moved to another slot or given another type.
+New variables may only be added after the existing ones.
+
+The earlier version is either a build directory, or a git ref whose contracts
+are compiled in a temporary worktree.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			Fatal("Must specify contract name")
+		}
+
+		against, _ := cmd.Flags().GetString("against")
+		if against == "" {
+			Fatal("Must specify a git ref or build directory to check against")
+		}
+
+		// Directories are relative to where the command is run, not the root
+		if fi, err := os.Stat(against); err == nil && fi.IsDir() {
+			abs, err := filepath.Abs(against)
+			if err != nil {
+				Fatal(err)
+			}
+			against = abs
+		}
+
+		err := RunInRoot(func() error {
+			return upgradeCheck(os.Stdout, args[0], against)
+		})
+		if err != nil {
+			Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(upgradeCheckCmd)
+
+	upgradeCheckCmd.Flags().String("against", "", "git ref or build directory of the earlier version")
+}
+
+// storageLayout is the storageLayout output of solc
+type storageLayout struct {
+	Storage []storageVariable      `json:"storage"`
+	Types   map[string]storageType `json:"types"`
+}
+
+type storageVariable struct {
+	Contract string `json:"contract"`
+	Label    string `json:"label"`
+	Offset   int    `json:"offset"`
+	Slot     string `json:"slot"`
+	Type     string `json:"type"`
+}
+
+type storageType struct {
+	Encoding      string            `json:"encoding"`
+	Label         string            `json:"label"`
+	NumberOfBytes string            `json:"numberOfBytes"`
+	Base          string            `json:"base"`
+	Key           string            `json:"key"`
+	Value         string            `json:"value"`
+	Members       []storageVariable `json:"members"`
+}
+
+// layoutChange is a difference between two storage layouts. Errors corrupt
+// existing state when upgrading, warnings don't but may be unintended
+type layoutChange struct {
+	Error    bool
+	Variable storageVariable
+	Message  string
+}
+
+func parseStorageLayout(name string, a *artifact) (*storageLayout, error) {
+	if len(a.StorageLayout) == 0 {
+		return nil, fmt.Errorf("No storage layout for %s, it needs solc 0.5.13 or later", name)
+	}
+
+	layout := &storageLayout{}
+	if err := json.Unmarshal(a.StorageLayout, layout); err != nil {
+		return nil, fmt.Errorf("%s: invalid storage layout: %v", name, err)
+	}
+
+	return layout, nil
+}
+
+func upgradeCheck(w io.Writer, name, against string) error {
+	a, err := loadArtifact(name)
+	if err != nil {
+		return fmt.Errorf("No artifacts for %s, build it with `wb compile`", name)
+	}
+
+	current, err := parseStorageLayout(name, a)
+	if err != nil {
+		return err
+	}
+
+	old, err := loadEarlierArtifact(name, against)
+	if err != nil {
+		return err
+	}
+
+	previous, err := parseStorageLayout(name, old)
+	if err != nil {
+		return err
+	}
+
+	changes := compareStorageLayouts(previous, current)
+
+	errs := 0
+	for _, change := range changes {
+		if change.Error {
+			errs++
+		}
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "%s: storage layout is compatible with %s\n", name, against)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s: storage layout changes against %s\n", name, against)
+	if err := printLayoutChanges(w, changes); err != nil {
+		return err
+	}
+
+	if errs > 0 {
+		return fmt.Errorf("Storage layout of %s is not compatible with %s", name, against)
+	}
+
+	return nil
+}
+
+// loadEarlierArtifact reads a contract's artifact from a build directory, or
+// builds the project as of a git ref to get it
+func loadEarlierArtifact(name, against string) (*artifact, error) {
+	if fi, err := os.Stat(against); err == nil && fi.IsDir() {
+		a, err := loadArtifactFrom(against, name)
+		if err != nil {
+			return nil, fmt.Errorf("No artifacts for %s in %s", name, against)
+		}
+		return a, nil
+	}
+
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", against+"^{commit}").Run(); err != nil {
+		return nil, fmt.Errorf("%s is neither a build directory nor a git ref", against)
+	}
+
+	// The project may be in a subdirectory of the repository
+	prefix, err := exec.Command("git", "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "wb-upgrade-check")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if out, err := exec.Command("git", "worktree", "add", "--detach", dir, against).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git worktree: %s", strings.TrimSpace(string(out)))
+	}
+	defer exec.Command("git", "worktree", "remove", "--force", dir).Run()
+
+	wb, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(dir, strings.TrimSpace(string(prefix)))
+
+	// Keep stdout for the report
+	build := exec.Command(wb, "compile", "--force")
+	build.Dir = root
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("Building %s failed: %v", against, err)
+	}
+
+	a, err := loadArtifactFrom(filepath.Join(root, project.BuildDirectory), name)
+	if err != nil {
+		return nil, fmt.Errorf("No contract %s at %s", name, against)
+	}
+
+	return a, nil
+}
+
+// storageName tells apart variables of the same name declared by different
+// contracts in the inheritance chain
+func storageName(v storageVariable) string {
+	return v.Contract + "." + v.Label
+}
+
+// compareStorageLayouts finds what would break reading state written by the
+// previous layout with the current one
+func compareStorageLayouts(previous, current *storageLayout) []layoutChange {
+	byPosition := make(map[string]storageVariable)
+	byLabel := make(map[string]storageVariable)
+	for _, v := range current.Storage {
+		byPosition[storagePosition(v)] = v
+		byLabel[storageName(v)] = v
+	}
+
+	previousLabels := make(map[string]bool)
+	for _, v := range previous.Storage {
+		previousLabels[storageName(v)] = true
+	}
+
+	changes := make([]layoutChange, 0)
+	for _, old := range previous.Storage {
+		at, ok := byPosition[storagePosition(old)]
+
+		if !ok || storageName(at) != storageName(old) {
+			if moved, ok := byLabel[storageName(old)]; ok {
+				changes = append(changes, layoutChange{
+					Error:    true,
+					Variable: old,
+					Message:  fmt.Sprintf("reordered to slot %s offset %d", moved.Slot, moved.Offset),
+				})
+				continue
+			}
+
+			if !ok || previousLabels[storageName(at)] {
+				changes = append(changes, layoutChange{Error: true, Variable: old, Message: "removed"})
+				continue
+			}
+
+			changes = append(changes, layoutChange{Variable: old, Message: "renamed to " + at.Label})
+		}
+
+		oldType := typeSignature(previous.Types, old.Type, 0)
+		newType := typeSignature(current.Types, at.Type, 0)
+		if oldType != newType {
+			from, to := typeLabel(previous.Types, old.Type), typeLabel(current.Types, at.Type)
+
+			message := fmt.Sprintf("retyped from %s to %s", from, to)
+			if from == to {
+				message = fmt.Sprintf("layout of %s changed", from)
+			}
+
+			changes = append(changes, layoutChange{Error: true, Variable: old, Message: message})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return storageBefore(changes[i].Variable, changes[j].Variable)
+	})
+
+	return changes
+}
+
+func printLayoutChanges(w io.Writer, changes []layoutChange) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, change := range changes {
+		severity := "warning"
+		if change.Error {
+			severity = "error"
+		}
+
+		fmt.Fprintf(tw, "  %s\tslot %s offset %d\t%s\t%s\n", severity, change.Variable.Slot, change.Variable.Offset, change.Variable.Label, change.Message)
+	}
+
+	return tw.Flush()
+}
+
+func storagePosition(v storageVariable) string {
+	return fmt.Sprintf("%s/%d", v.Slot, v.Offset)
+}
+
+// storageBefore orders variables by slot, which are decimal strings that can
+// be too large for an int, then offset
+func storageBefore(a, b storageVariable) bool {
+	if len(a.Slot) != len(b.Slot) {
+		return len(a.Slot) < len(b.Slot)
+	}
+	if a.Slot != b.Slot {
+		return a.Slot < b.Slot
+	}
+
+	return a.Offset < b.Offset
+}
+
+func typeLabel(types map[string]storageType, id string) string {
+	if t, ok := types[id]; ok && t.Label != "" {
+		return t.Label
+	}
+
+	return id
+}
+
+// maxTypeDepth bounds typeSignature, since a struct can contain a mapping to
+// itself
+const maxTypeDepth = 8
+
+// typeSignature describes how a type is stored, including the layout of the
+// members of structs, which their labels alone don't show
+func typeSignature(types map[string]storageType, id string, depth int) string {
+	t, ok := types[id]
+	if !ok || depth > maxTypeDepth {
+		return id
+	}
+
+	signature := t.Label + ":" + t.Encoding + ":" + t.NumberOfBytes
+	if t.Key != "" {
+		signature += "(" + typeSignature(types, t.Key, depth+1) + "=>" + typeSignature(types, t.Value, depth+1) + ")"
+	}
+	if t.Base != "" {
+		signature += "[" + typeSignature(types, t.Base, depth+1) + "]"
+	}
+	if len(t.Members) > 0 {
+		members := make([]string, 0, len(t.Members))
+		for _, m := range t.Members {
+			members = append(members, storagePosition(m)+" "+m.Label+" "+typeSignature(types, m.Type, depth+1))
+		}
+		signature += "{" + strings.Join(members, ", ") + "}"
+	}
+
+	return signature
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	testBase  = "contracts/Base.sol:Base"
+	testToken = "contracts/Token.sol:Token"
+)
+
+func testLayoutTypes(posMembers ...storageVariable) map[string]storageType {
+	return map[string]storageType{
+		"t_uint256": {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+		"t_uint128": {Encoding: "inplace", Label: "uint128", NumberOfBytes: "16"},
+		"t_address": {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+		"t_mapping(t_address,t_uint256)": {
+			Encoding: "mapping", Label: "mapping(address => uint256)", NumberOfBytes: "32",
+			Key: "t_address", Value: "t_uint256",
+		},
+		"t_struct(Pos)1_storage": {
+			Encoding: "inplace", Label: "struct Token.Pos", NumberOfBytes: "64",
+			Members: posMembers,
+		},
+	}
+}
+
+func testVariable(contract, label, slot string, offset int, typ string) storageVariable {
+	return storageVariable{Contract: contract, Label: label, Slot: slot, Offset: offset, Type: typ}
+}
+
+func TestCompareStorageLayouts(t *testing.T) {
+	owner := testVariable(testBase, "owner", "0", 0, "t_address")
+	balances := testVariable(testToken, "balances", "1", 0, "t_mapping(t_address,t_uint256)")
+	supply := testVariable(testToken, "supply", "2", 0, "t_uint256")
+	pos := []storageVariable{
+		testVariable(testToken, "x", "0", 0, "t_uint256"),
+		testVariable(testToken, "y", "1", 0, "t_uint256"),
+	}
+
+	tests := []struct {
+		name     string
+		previous []storageVariable
+		current  []storageVariable
+		members  []storageVariable
+		want     []layoutChange
+	}{
+		{
+			name:     "unchanged",
+			previous: []storageVariable{owner, balances, supply},
+			current:  []storageVariable{owner, balances, supply},
+			want:     []layoutChange{},
+		},
+		{
+			name:     "appended",
+			previous: []storageVariable{owner, balances},
+			current:  []storageVariable{owner, balances, supply},
+			want:     []layoutChange{},
+		},
+		{
+			name:     "renamed",
+			previous: []storageVariable{owner, balances, supply},
+			current:  []storageVariable{owner, balances, testVariable(testToken, "totalSupply", "2", 0, "t_uint256")},
+			want:     []layoutChange{{Variable: supply, Message: "renamed to totalSupply"}},
+		},
+		{
+			name:     "removed",
+			previous: []storageVariable{owner, balances, supply},
+			current:  []storageVariable{owner, balances},
+			want:     []layoutChange{{Error: true, Variable: supply, Message: "removed"}},
+		},
+		{
+			name:     "replaced by another variable",
+			previous: []storageVariable{owner, balances, supply},
+			current:  []storageVariable{owner, testVariable(testToken, "supply", "1", 0, "t_uint256")},
+			want: []layoutChange{
+				{Error: true, Variable: balances, Message: "removed"},
+				{Error: true, Variable: supply, Message: "reordered to slot 1 offset 0"},
+			},
+		},
+		{
+			name:     "reordered",
+			previous: []storageVariable{owner, balances, supply},
+			current: []storageVariable{
+				owner,
+				testVariable(testToken, "supply", "1", 0, "t_uint256"),
+				testVariable(testToken, "balances", "2", 0, "t_mapping(t_address,t_uint256)"),
+			},
+			want: []layoutChange{
+				{Error: true, Variable: balances, Message: "reordered to slot 2 offset 0"},
+				{Error: true, Variable: supply, Message: "reordered to slot 1 offset 0"},
+			},
+		},
+		{
+			name:     "retyped",
+			previous: []storageVariable{owner, balances, supply},
+			current:  []storageVariable{owner, balances, testVariable(testToken, "supply", "2", 0, "t_uint128")},
+			want:     []layoutChange{{Error: true, Variable: supply, Message: "retyped from uint256 to uint128"}},
+		},
+		{
+			name:     "struct members reordered",
+			previous: []storageVariable{owner, testVariable(testToken, "pos", "1", 0, "t_struct(Pos)1_storage")},
+			current:  []storageVariable{owner, testVariable(testToken, "pos", "1", 0, "t_struct(Pos)1_storage")},
+			members: []storageVariable{
+				testVariable(testToken, "y", "0", 0, "t_uint256"),
+				testVariable(testToken, "x", "1", 0, "t_uint256"),
+			},
+			want: []layoutChange{{
+				Error:    true,
+				Variable: testVariable(testToken, "pos", "1", 0, "t_struct(Pos)1_storage"),
+				Message:  "layout of struct Token.Pos changed",
+			}},
+		},
+		{
+			name: "same name in another contract",
+			previous: []storageVariable{
+				testVariable(testBase, "paused", "0", 0, "t_uint256"),
+				testVariable(testToken, "paused", "1", 0, "t_uint256"),
+			},
+			current: []storageVariable{
+				testVariable(testBase, "paused", "0", 0, "t_uint256"),
+				testVariable(testToken, "halted", "1", 0, "t_uint256"),
+			},
+			want: []layoutChange{{Variable: testVariable(testToken, "paused", "1", 0, "t_uint256"), Message: "renamed to halted"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			members := test.members
+			if members == nil {
+				members = pos
+			}
+
+			previous := &storageLayout{Storage: test.previous, Types: testLayoutTypes(pos...)}
+			current := &storageLayout{Storage: test.current, Types: testLayoutTypes(members...)}
+
+			got := compareStorageLayouts(previous, current)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("compareStorageLayouts() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTypeSignature(t *testing.T) {
+	types := testLayoutTypes(
+		testVariable(testToken, "x", "0", 0, "t_uint256"),
+		testVariable(testToken, "y", "1", 0, "t_uint256"),
+	)
+	types["t_array(t_address)dyn_storage"] = storageType{Encoding: "dynamic_array", Label: "address[]", NumberOfBytes: "32", Base: "t_address"}
+	types["t_struct(Node)2_storage"] = storageType{
+		Encoding: "inplace", Label: "struct Token.Node", NumberOfBytes: "32",
+		Members: []storageVariable{testVariable(testToken, "next", "0", 0, "t_mapping(t_uint256,t_struct(Node)2_storage)")},
+	}
+	types["t_mapping(t_uint256,t_struct(Node)2_storage)"] = storageType{
+		Encoding: "mapping", Label: "mapping(uint256 => struct Token.Node)", NumberOfBytes: "32",
+		Key: "t_uint256", Value: "t_struct(Node)2_storage",
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"t_uint256", "uint256:inplace:32"},
+		{"t_unknown", "t_unknown"},
+		{"t_mapping(t_address,t_uint256)", "mapping(address => uint256):mapping:32(address:inplace:20=>uint256:inplace:32)"},
+		{"t_array(t_address)dyn_storage", "address[]:dynamic_array:32[address:inplace:20]"},
+		{"t_struct(Pos)1_storage", "struct Token.Pos:inplace:64{0/0 x uint256:inplace:32, 1/0 y uint256:inplace:32}"},
+	}
+
+	for _, test := range tests {
+		if got := typeSignature(types, test.id, 0); got != test.want {
+			t.Errorf("typeSignature(%s) = %s, want %s", test.id, got, test.want)
+		}
+	}
+
+	// Recursive structs are cut off rather than followed forever
+	if got := typeSignature(types, "t_struct(Node)2_storage", 0); got == "" {
+		t.Errorf("typeSignature of a recursive struct is empty")
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/zscole/cli/project"
+)
+
+// watchDebounce is how long the project has to be quiet after a change before
+// a cycle runs, so saving several files at once only triggers one
+const watchDebounce = 300 * time.Millisecond
+
+// watchStage is a step of the pipeline a change needs to rerun from
+type watchStage int
+
+const (
+	stageNone watchStage = iota
+	stageTest
+	stageCompile
+)
+
+// stageResult is the outcome of a pipeline stage in one cycle
+type stageResult struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+// watch runs the compile pipeline, and the tests too if asked to, every time a
+// watched file changes, until interrupted. Failing cycles are reported rather
+// than ending the process
+func watch(test bool) error {
+	dirs := []string{project.ContractsDirectory}
+	if test {
+		dirs = append(dirs, project.MigrationsDirectory, project.TestsDirectory)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for _, dir := range dirs {
+		if err := watchDirectory(watcher, dir); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Watching", strings.Join(dirs, ", "), "for changes")
+	runCycle(stageCompile, test)
+
+	pending := stageNone
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// New directories need watching themselves, since watches aren't
+			// recursive
+			if event.Op&fsnotify.Create != 0 {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					if err := watchDirectory(watcher, event.Name); err != nil {
+						fmt.Println("Error:", err)
+					}
+				}
+			}
+
+			if stage := changedStage(event, test); stage > pending {
+				pending = stage
+			}
+			if pending != stageNone {
+				timer.Reset(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("Error:", err)
+
+		case <-timer.C:
+			runCycle(pending, test)
+			pending = stageNone
+		}
+	}
+}
+
+func watchDirectory(watcher *fsnotify.Watcher, dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		return watcher.Add(path)
+	})
+}
+
+// changedStage is the earliest stage a change to a file affects
+func changedStage(event fsnotify.Event, test bool) watchStage {
+	if event.Op == fsnotify.Chmod {
+		return stageNone
+	}
+
+	// Editors write through temporary and hidden files
+	base := filepath.Base(event.Name)
+	if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") {
+		return stageNone
+	}
+
+	path := filepath.ToSlash(filepath.Clean(event.Name))
+	switch {
+	case strings.HasPrefix(path, project.ContractsDirectory+"/"):
+		if backendForFile(path) != nil || filepath.Ext(path) == "" {
+			return stageCompile
+		}
+	case test && (strings.HasPrefix(path, project.MigrationsDirectory+"/") || strings.HasPrefix(path, project.TestsDirectory+"/")):
+		if filepath.Ext(path) == ".go" || filepath.Ext(path) == "" {
+			return stageTest
+		}
+	}
+
+	return stageNone
+}
+
+// runCycle reruns the pipeline from a stage and prints a summary of how it went
+func runCycle(from watchStage, test bool) {
+	results := make([]stageResult, 0, 2)
+
+	if from >= stageCompile {
+		results = append(results, runStage("compile", compile))
+	}
+
+	if test && (len(results) == 0 || results[0].err == nil) {
+		results = append(results, runStage("test", func() error {
+			return runStub("test")
+		}))
+	}
+
+	parts := make([]string, 0, len(results))
+	for _, result := range results {
+		status := "ok"
+		if result.err != nil {
+			status = "FAIL"
+		}
+
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", result.name, status, result.duration.Round(time.Millisecond)))
+	}
+
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), strings.Join(parts, ", "))
+}
+
+func runStage(name string, f func() error) stageResult {
+	start := time.Now()
+	err := f()
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	return stageResult{name: name, err: err, duration: time.Since(start)}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestChangedStage(t *testing.T) {
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		test bool
+		want watchStage
+	}{
+		{"contracts/Token.sol", fsnotify.Write, false, stageCompile},
+		{"contracts/token/Token.sol", fsnotify.Create, true, stageCompile},
+		{"contracts/Token.vy", fsnotify.Write, false, stageCompile},
+		{"contracts/token", fsnotify.Remove, false, stageCompile},
+		{"contracts/Token.sol", fsnotify.Chmod, false, stageNone},
+		{"contracts/README.md", fsnotify.Write, false, stageNone},
+		{"contracts/.Token.sol.swp", fsnotify.Write, false, stageNone},
+		{"contracts/Token.sol~", fsnotify.Write, false, stageNone},
+		{"./contracts/Token.sol", fsnotify.Write, false, stageCompile},
+		{"tests/token_test.go", fsnotify.Write, true, stageTest},
+		{"tests/token_test.go", fsnotify.Write, false, stageNone},
+		{"migrations/2_Token.go", fsnotify.Write, true, stageTest},
+		{"migrations/old", fsnotify.Rename, true, stageTest},
+		{"migrations/notes.txt", fsnotify.Write, true, stageNone},
+		{"build/Token.bin", fsnotify.Write, true, stageNone},
+		{"contractsold/Token.sol", fsnotify.Write, true, stageNone},
+	}
+
+	for _, test := range tests {
+		event := fsnotify.Event{Name: test.name, Op: test.op}
+		if got := changedStage(event, test.test); got != test.want {
+			t.Errorf("changedStage(%s %s, test %v) = %v, want %v", test.op, test.name, test.test, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package deployer picks the keystore account migrations deploy from and
+// unlocks it, without a prompt when its passphrase is given through the
+// environment, as in CI.
+//
+// `wb migrate` sets AccountEnv from the deployer of the network in wb.yaml,
+// and PasswordFileEnv from its password_file unless a passphrase is already
+// given
+package deployer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// AccountEnv is the address of the account to deploy from, or its index
+	// in the keystore. The first account is used when it's unset
+	AccountEnv = "WB_DEPLOYER"
+
+	// PasswordEnv is the passphrase of the deployer
+	PasswordEnv = "WB_PASSWORD"
+
+	// PasswordFileEnv is a file holding the passphrase of the deployer, used
+	// when PasswordEnv is unset
+	PasswordFileEnv = "WB_PASSWORD_FILE"
+)
+
+// Network is the part of a perigord network the deployer is picked from. How
+// it's unlocked depends on the methods the network has, so migrations build
+// against any perigord
+type Network interface {
+	Accounts() []accounts.Account
+}
+
+// passphraseUnlocker is a network that unlocks accounts with a passphrase
+type passphraseUnlocker interface {
+	Unlock(account accounts.Account, passphrase string) error
+}
+
+// promptUnlocker is a network that prompts for the passphrase of an account
+type promptUnlocker interface {
+	UnlockWithPrompt(account accounts.Account) error
+}
+
+// legacyPromptUnlocker is a network that prompts for the passphrase of an
+// account without reporting whether it was unlocked
+type legacyPromptUnlocker interface {
+	UnlockWithPrompt(account accounts.Account)
+}
+
+// Unlock unlocks the deployer in a network's keystore and returns it
+func Unlock(network Network) (accounts.Account, error) {
+	account, err := Select(network.Accounts(), os.Getenv(AccountEnv))
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	passphrase, ok, err := Password()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	if ok {
+		unlocker, can := network.(passphraseUnlocker)
+		if !can {
+			return accounts.Account{}, fmt.Errorf("This version of perigord can't unlock %s with a passphrase, unset %s and %s to be prompted for it", account.Address.Hex(), PasswordEnv, PasswordFileEnv)
+		}
+
+		return account, unlocker.Unlock(account, passphrase)
+	}
+
+	switch unlocker := network.(type) {
+	case promptUnlocker:
+		err = unlocker.UnlockWithPrompt(account)
+	case legacyPromptUnlocker:
+		unlocker.UnlockWithPrompt(account)
+	default:
+		err = fmt.Errorf("This version of perigord can't unlock %s", account.Address.Hex())
+	}
+
+	return account, err
+}
+
+// Select returns the account a deployer setting names, an address or an
+// index into the accounts, or the first account if it's empty
+func Select(all []accounts.Account, deployer string) (accounts.Account, error) {
+	if len(all) == 0 {
+		return accounts.Account{}, fmt.Errorf("No accounts in the keystore, create one with `wb accounts new`")
+	}
+
+	if deployer == "" {
+		return all[0], nil
+	}
+
+	if common.IsHexAddress(deployer) {
+		address := common.HexToAddress(deployer)
+		for _, account := range all {
+			if account.Address == address {
+				return account, nil
+			}
+		}
+
+		return accounts.Account{}, fmt.Errorf("Deployer %s isn't in the keystore", address.Hex())
+	}
+
+	i, err := strconv.Atoi(deployer)
+	if err != nil || i < 0 {
+		return accounts.Account{}, fmt.Errorf("Invalid deployer %q, must be an address or an index into the keystore", deployer)
+	}
+
+	if i >= len(all) {
+		return accounts.Account{}, fmt.Errorf("No deployer %d, the keystore has %d accounts", i, len(all))
+	}
+
+	return all[i], nil
+}
+
+// Password returns the passphrase given through the environment, and whether
+// there is one
+func Password() (string, bool, error) {
+	if passphrase, ok := os.LookupEnv(PasswordEnv); ok {
+		return passphrase, true, nil
+	}
+
+	if path := os.Getenv(PasswordFileEnv); path != "" {
+		passphrase, err := ReadPasswordFile(path)
+		return passphrase, err == nil, err
+	}
+
+	return "", false, nil
+}
+
+// ReadPasswordFile reads a passphrase from the first line of a file
+func ReadPasswordFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Reading password file: %v", err)
+	}
+
+	return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+}
@@ -0,0 +1,196 @@
+package deployer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testAccounts() []accounts.Account {
+	return []accounts.Account{
+		{Address: common.HexToAddress("0x1111111111111111111111111111111111111111")},
+		{Address: common.HexToAddress("0x2222222222222222222222222222222222222222")},
+		{Address: common.HexToAddress("0x3333333333333333333333333333333333333333")},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	all := testAccounts()
+
+	tests := []struct {
+		name     string
+		accounts []accounts.Account
+		deployer string
+		want     common.Address
+		err      bool
+	}{
+		{name: "first by default", accounts: all, want: all[0].Address},
+		{name: "index", accounts: all, deployer: "2", want: all[2].Address},
+		{name: "address", accounts: all, deployer: "0x2222222222222222222222222222222222222222", want: all[1].Address},
+		{name: "address without prefix", accounts: all, deployer: "3333333333333333333333333333333333333333", want: all[2].Address},
+		{name: "address in another case", accounts: all, deployer: "0X2222222222222222222222222222222222222222", want: all[1].Address},
+		{name: "address not in the keystore", accounts: all, deployer: "0x4444444444444444444444444444444444444444", err: true},
+		{name: "index out of range", accounts: all, deployer: "3", err: true},
+		{name: "negative index", accounts: all, deployer: "-1", err: true},
+		{name: "neither", accounts: all, deployer: "alice", err: true},
+		{name: "empty keystore", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Select(test.accounts, test.deployer)
+			if (err != nil) != test.err {
+				t.Fatalf("Select(%q) error = %v, want error %v", test.deployer, err, test.err)
+			}
+
+			if err == nil && got.Address != test.want {
+				t.Errorf("Select(%q) = %s, want %s", test.deployer, got.Address.Hex(), test.want.Hex())
+			}
+		})
+	}
+}
+
+// testNetwork records how the deployer was unlocked
+type testNetwork struct {
+	unlocked   string
+	passphrase string
+	err        error
+}
+
+func (n *testNetwork) Accounts() []accounts.Account {
+	return testAccounts()
+}
+
+func (n *testNetwork) Unlock(account accounts.Account, passphrase string) error {
+	n.unlocked, n.passphrase = account.Address.Hex(), passphrase
+	return n.err
+}
+
+func (n *testNetwork) UnlockWithPrompt(account accounts.Account) error {
+	n.unlocked = account.Address.Hex()
+	return n.err
+}
+
+// promptNetwork can only prompt for passphrases
+type promptNetwork struct {
+	unlocked string
+}
+
+func (n *promptNetwork) Accounts() []accounts.Account {
+	return testAccounts()
+}
+
+func (n *promptNetwork) UnlockWithPrompt(account accounts.Account) {
+	n.unlocked = account.Address.Hex()
+}
+
+func TestUnlock(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("from file\r\nignored\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	second := testAccounts()[1].Address.Hex()
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		network    Network
+		unlocked   string
+		passphrase string
+		err        bool
+	}{
+		{
+			name:     "prompt",
+			env:      map[string]string{AccountEnv: "1"},
+			network:  &testNetwork{},
+			unlocked: second,
+		},
+		{
+			name:       "passphrase",
+			env:        map[string]string{AccountEnv: "1", PasswordEnv: "secret"},
+			network:    &testNetwork{},
+			unlocked:   second,
+			passphrase: "secret",
+		},
+		{
+			name:     "empty passphrase",
+			env:      map[string]string{AccountEnv: "1", PasswordEnv: ""},
+			network:  &testNetwork{},
+			unlocked: second,
+		},
+		{
+			name:       "password file",
+			env:        map[string]string{AccountEnv: "1", PasswordFileEnv: passwordFile},
+			network:    &testNetwork{},
+			unlocked:   second,
+			passphrase: "from file",
+		},
+		{
+			name:    "wrong passphrase",
+			env:     map[string]string{AccountEnv: "1", PasswordEnv: "wrong"},
+			network: &testNetwork{err: errors.New("could not decrypt key with given password")},
+			err:     true,
+		},
+		{
+			name:     "prompt without an error",
+			env:      map[string]string{AccountEnv: "1"},
+			network:  &promptNetwork{},
+			unlocked: second,
+		},
+		{
+			name:    "passphrase without a way to use it",
+			env:     map[string]string{AccountEnv: "1", PasswordEnv: "secret"},
+			network: &promptNetwork{},
+			err:     true,
+		},
+		{
+			name:    "no such deployer",
+			env:     map[string]string{AccountEnv: "5"},
+			network: &testNetwork{},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{AccountEnv, PasswordEnv, PasswordFileEnv} {
+				value, ok := test.env[key]
+				if ok {
+					t.Setenv(key, value)
+				} else {
+					t.Setenv(key, "")
+					os.Unsetenv(key)
+				}
+			}
+
+			account, err := Unlock(test.network)
+			if (err != nil) != test.err {
+				t.Fatalf("Unlock() error = %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if account.Address.Hex() != second {
+				t.Errorf("Unlock() = %s, want %s", account.Address.Hex(), second)
+			}
+
+			switch n := test.network.(type) {
+			case *testNetwork:
+				if n.unlocked != test.unlocked || n.passphrase != test.passphrase {
+					t.Errorf("unlocked %s with %q, want %s with %q", n.unlocked, n.passphrase, test.unlocked, test.passphrase)
+				}
+			case *promptNetwork:
+				if n.unlocked != test.unlocked {
+					t.Errorf("unlocked %s, want %s", n.unlocked, test.unlocked)
+				}
+			}
+		})
+	}
+}
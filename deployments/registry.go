@@ -0,0 +1,204 @@
+// Package deployments records what has been deployed to each network, in one
+// json file per network under the project's deployments directory.
+//
+// The cli records libraries it deploys itself, and migrations record their
+// contracts while `wb migrate` runs them
+package deployments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Directory is where registries live, relative to the project root
+const Directory = "deployments"
+
+// LinkedDirectory holds the bytecode of contracts that use libraries, linked
+// for each network in a directory of its own, relative to the project root
+const LinkedDirectory = "build/linked"
+
+// NetworkEnv names the network migrations are being run on. `wb migrate` sets
+// it for the stub, and nothing is recorded when it's unset, e.g. in tests
+const NetworkEnv = "WB_NETWORK"
+
+// Library is a deployed solidity library that contracts are linked against
+type Library struct {
+	Address     string `json:"address"`
+	Transaction string `json:"transaction,omitempty"`
+}
+
+// Contract is a contract deployed by a migration
+type Contract struct {
+	Address      string    `json:"address"`
+	Transaction  string    `json:"transaction"`
+	Block        uint64    `json:"block"`
+	BytecodeHash string    `json:"bytecode_hash"`
+	Migration    int       `json:"migration"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Migration is a migration that has run on the network
+type Migration struct {
+	Number    int       `json:"number"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Registry is the deployment state of a single network
+type Registry struct {
+	Network    string              `json:"network"`
+	Libraries  map[string]Library  `json:"libraries,omitempty"`
+	Contracts  map[string]Contract `json:"contracts,omitempty"`
+	Migrations []Migration         `json:"migrations,omitempty"`
+
+	path string
+}
+
+// LinkedPath returns the file holding a contract's bytecode linked for a
+// network within a project
+func LinkedPath(root, network, name string) string {
+	return filepath.Join(root, LinkedDirectory, network, name+".bin")
+}
+
+// Path returns the registry file for a network within a project
+func Path(root, network string) string {
+	return filepath.Join(root, Directory, network+".json")
+}
+
+// Load reads the registry for a network, returning an empty registry if the
+// network has no deployments yet
+func Load(root, network string) (*Registry, error) {
+	r := &Registry{
+		Network: network,
+		path:    Path(root, network),
+	}
+
+	data, err := ioutil.ReadFile(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Libraries == nil {
+		r.Libraries = make(map[string]Library)
+	}
+	if r.Contracts == nil {
+		r.Contracts = make(map[string]Contract)
+	}
+
+	return r, nil
+}
+
+// Save writes the registry back to its file
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// AddMigration records that a migration has run, replacing any earlier run
+func (r *Registry) AddMigration(number int, timestamp time.Time) {
+	migrations := make([]Migration, 0, len(r.Migrations)+1)
+	for _, m := range r.Migrations {
+		if m.Number != number {
+			migrations = append(migrations, m)
+		}
+	}
+	migrations = append(migrations, Migration{Number: number, Timestamp: timestamp})
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Number < migrations[j].Number
+	})
+	r.Migrations = migrations
+}
+
+// Reset forgets the contracts and migrations of the network, for when every
+// migration is about to be run again
+func (r *Registry) Reset() {
+	r.Contracts = make(map[string]Contract)
+	r.Migrations = nil
+}
+
+// RecordContract records a contract deployed by a migration in the registry of
+// the network being migrated
+func RecordContract(name string, contract Contract) error {
+	network := os.Getenv(NetworkEnv)
+	if network == "" {
+		return nil
+	}
+
+	r, err := Load(".", network)
+	if err != nil {
+		return err
+	}
+
+	if contract.Timestamp.IsZero() {
+		contract.Timestamp = time.Now().UTC()
+	}
+	r.Contracts[name] = contract
+
+	return r.Save()
+}
+
+// RecordMigration records that a migration has run on the network being
+// migrated
+func RecordMigration(number int) error {
+	network := os.Getenv(NetworkEnv)
+	if network == "" {
+		return nil
+	}
+
+	r, err := Load(".", network)
+	if err != nil {
+		return err
+	}
+
+	r.AddMigration(number, time.Now().UTC())
+	return r.Save()
+}
+
+// BytecodeHash identifies deployment bytecode, given as hex with or without a
+// 0x prefix, so it can be compared with the build artifacts
+func BytecodeHash(bytecode string) string {
+	bytecode = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(bytecode), "0x"))
+	sum := sha256.Sum256([]byte(bytecode))
+	return hex.EncodeToString(sum[:])
+}
+
+// LinkedBytecode returns a contract's bytecode linked for the network being
+// migrated, for migrations to deploy in place of the unlinked bytecode in its
+// binding. It's empty for contracts that don't use libraries
+func LinkedBytecode(name string) (string, error) {
+	network := os.Getenv(NetworkEnv)
+	if network == "" {
+		return "", nil
+	}
+
+	data, err := ioutil.ReadFile(LinkedPath(".", network, name))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
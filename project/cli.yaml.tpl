@@ -1,7 +0,0 @@
-project: {{.project}}
-license: {{.license.Name}}
-
-networks:
-    dev:
-        url: /tmp/geth_private_testnet/geth.ipc
-        keystore: /tmp/geth_private_testnet/keystore
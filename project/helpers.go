@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/zscole/cli/deployments"
 )
 
 const (
@@ -14,6 +16,8 @@ const (
 	BindingsDirectory     = "bindings"
 	MigrationsDirectory   = "migrations"
 	TestsDirectory        = "tests"
+	DeploymentsDirectory  = deployments.Directory
+	GoModFilename         = "go.mod"
 )
 
 func exists(path string) (bool, error) {
@@ -0,0 +1,137 @@
+package project
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The modules the code of a new project imports
+const (
+	CLIModule      = "github.com/zscole/cli"
+	GethModule     = "github.com/ethereum/go-ethereum"
+	PerigordModule = "github.com/polyswarm/perigord"
+)
+
+// defaultGoVersion is used for development toolchains that don't report a
+// release version
+const defaultGoVersion = "1.16"
+
+var goVersionPattern = regexp.MustCompile(`^go(\d+\.\d+)`)
+
+// readModulePath returns the module path declared in a go.mod file, or an
+// empty string if there is no such file or it declares no module
+func readModulePath(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if module, err := strconv.Unquote(fields[1]); err == nil {
+			return module
+		}
+		return fields[1]
+	}
+
+	return ""
+}
+
+// goVersion is the language version of the running toolchain for go.mod's go
+// directive, e.g. "1.21" for go1.21.3
+func goVersion() string {
+	if m := goVersionPattern.FindStringSubmatch(runtime.Version()); m != nil {
+		return m[1]
+	}
+
+	return defaultGoVersion
+}
+
+// Module is a requirement in a project's go.mod
+type Module struct {
+	Path    string
+	Version string
+}
+
+// RequiredModules returns the modules the code of a new project imports, at
+// the versions this build of the cli was built with, which the templates are
+// known to work with. A module the cli isn't built with is pinned to its
+// latest version if the go command can find it in time, and otherwise left
+// for `go mod tidy` to add
+func RequiredModules() []Module {
+	versions := make(map[string]string)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		// A cli built from a checkout has no version, or one stamped from
+		// a modified checkout that can't be fetched
+		if info.Main.Version != "(devel)" && !strings.HasSuffix(info.Main.Version, "+dirty") {
+			versions[info.Main.Path] = info.Main.Version
+		}
+
+		for _, dep := range info.Deps {
+			versions[dep.Path] = dep.Version
+		}
+	}
+
+	modules := make([]Module, 0, 3)
+	for _, path := range []string{CLIModule, GethModule, PerigordModule} {
+		version := versions[path]
+		if version == "" {
+			version = latestVersion(path)
+		}
+
+		if version != "" {
+			modules = append(modules, Module{Path: path, Version: version})
+		}
+	}
+
+	return modules
+}
+
+// latestVersionTimeout bounds looking up a module's latest version, which
+// takes the network, so `wb init` doesn't hang offline
+var latestVersionTimeout = 10 * time.Second
+
+// latestVersion returns the latest version of a module, or an empty string if
+// there's no go command on the path or it can't find one in time
+func latestVersion(path string) string {
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), latestVersionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, goCommand, "list", "-m", "-f", "{{.Version}}", path+"@latest")
+	cmd.Dir = os.TempDir()
+	cmd.WaitDelay = time.Second
+
+	// Never wait on a prompt for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
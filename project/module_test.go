@@ -0,0 +1,62 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakeGo puts a go command running script first on the path
+func fakeGo(t *testing.T, script string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "go"), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestLatestVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake go command is a shell script")
+	}
+
+	timeout := latestVersionTimeout
+	latestVersionTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { latestVersionTimeout = timeout })
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"found", "echo v1.2.3", "v1.2.3"},
+		{"offline", "echo 'dial tcp: lookup proxy.golang.org: no such host' >&2; exit 1", ""},
+		{"too slow", "sleep 30; echo v1.2.3", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeGo(t, test.script)
+
+			start := time.Now()
+			if got := latestVersion(PerigordModule); got != test.want {
+				t.Errorf("latestVersion() = %q, want %q", got, test.want)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("latestVersion() took %s", elapsed)
+			}
+		})
+	}
+
+	t.Run("no go command", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		if got := latestVersion(PerigordModule); got != "" {
+			t.Errorf("latestVersion() = %q, want none", got)
+		}
+	})
+}
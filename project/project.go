@@ -1,22 +1,19 @@
 package project
 
 import (
-	"fmt"
+	"go/build"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 )
 
+// srcPaths are the src directories of every $GOPATH entry, which projects no
+// longer need to live in but are still named relative to if they do
 var srcPaths []string
 
 func init() {
-	envGoPath := os.Getenv("GOPATH")
-	goPaths := filepath.SplitList(envGoPath)
-	if len(goPaths) == 0 {
-		fmt.Println("$GOPATH is not set")
-		os.Exit(1)
-	}
+	goPaths := filepath.SplitList(build.Default.GOPATH)
 	srcPaths = make([]string, 0, len(goPaths))
 	for _, goPath := range goPaths {
 		srcPaths = append(srcPaths, filepath.Join(goPath, "src"))
@@ -28,6 +25,7 @@ type Project struct {
 	srcPath string
 	license License
 	name    string
+	module  string
 }
 
 func NewProject(projectName string) *Project {
@@ -54,8 +52,14 @@ func NewProject(projectName string) *Project {
 		}
 	}
 
+	// Outside of $GOPATH the project is created in the current directory, and
+	// its name becomes the module path
 	if p.absPath == "" {
-		p.absPath = filepath.Join(srcPaths[0], projectName)
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+		p.absPath = filepath.Join(wd, filepath.Base(filepath.FromSlash(projectName)))
 	}
 
 	return p
@@ -87,13 +91,16 @@ func NewProjectFromPath(absPath string) *Project {
 	return p
 }
 
+// trimSrcPath names a project by its path within $GOPATH, or by its directory
+// if it lives elsewhere
 func trimSrcPath(absPath, srcPath string) string {
-	relPath, err := filepath.Rel(srcPath, absPath)
-	if err != nil {
-		fmt.Println("Cobra supports project only within $GOPATH: " + err.Error())
-		os.Exit(1)
+	if srcPath != "" {
+		if relPath, err := filepath.Rel(srcPath, absPath); err == nil {
+			return relPath
+		}
 	}
-	return relPath
+
+	return filepath.Base(absPath)
 }
 
 func (p *Project) License() License {
@@ -104,10 +111,24 @@ func (p *Project) License() License {
 	return p.license
 }
 
+// Name is the import path of the project, its module path if it has a go.mod
 func (p Project) Name() string {
+	if p.module != "" {
+		return p.module
+	}
+
+	if module := readModulePath(filepath.Join(p.absPath, GoModFilename)); module != "" {
+		return module
+	}
+
 	return p.name
 }
 
+// SetModulePath overrides the module path of a project that has no go.mod yet
+func (p *Project) SetModulePath(module string) {
+	p.module = module
+}
+
 func (p Project) AbsPath() string {
 	return p.absPath
 }
@@ -118,7 +139,9 @@ func (p *Project) SrcPath() string {
 	}
 
 	if p.absPath == "" {
-		p.srcPath = srcPaths[0]
+		if len(srcPaths) > 0 {
+			p.srcPath = srcPaths[0]
+		}
 		return p.srcPath
 	}
 
@@ -135,6 +158,7 @@ func (p *Project) SrcPath() string {
 func (p *Project) TemplateData() map[string]interface{} {
 	return map[string]interface{}{
 		"project":   p.Name(),
+		"go":        goVersion(),
 		"license":   p.License(),
 		"copyright": copyrightLine(),
 	}
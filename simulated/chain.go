@@ -0,0 +1,306 @@
+// Package simulated is an in-process chain for tests and `wb node`, backed by
+// go-ethereum's simulated backend, so neither needs a separate node.
+//
+// The chain starts with deterministic accounts funded in its genesis block,
+// and by default mines every transaction into a block of its own as soon as
+// it's sent. Snapshots let a suite set up contracts once and start every test
+// from the same state
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ConfigEnv holds the chain's configuration as json. `wb test` sets it from
+// the test network in wb.yaml
+const ConfigEnv = "WB_SIMULATED"
+
+const (
+	// DefaultAccounts is how many accounts are funded unless configured
+	DefaultAccounts = 10
+
+	// DefaultGasLimit is the gas limit of every block
+	DefaultGasLimit = 30000000
+)
+
+// DefaultBalance is what each account is funded with unless configured, 10000
+// ether
+var DefaultBalance = new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18))
+
+// Config is the genesis state of a chain, and how it mines
+type Config struct {
+	Accounts int      `json:"accounts"`
+	Balance  *big.Int `json:"balance"`
+	GasLimit uint64   `json:"gas_limit"`
+
+	// ChainID replaces the simulated backend's chain id of 1337
+	ChainID *big.Int `json:"chain_id"`
+
+	// Manual leaves sent transactions pending until Mine is called
+	Manual bool `json:"manual"`
+
+	// Alloc adds accounts to the genesis block alongside the funded ones,
+	// such as state copied from another network
+	Alloc types.GenesisAlloc `json:"alloc,omitempty"`
+
+	// Proofs lets the chain answer eth_getProof, which the simulated backend
+	// has no method for, by having its node serve over IPC in a temporary
+	// directory until the chain is closed
+	Proofs bool `json:"-"`
+}
+
+// Chain is a simulated chain. It's a bind.ContractBackend and
+// bind.DeployBackend, so bindings deploy and call contracts on it directly
+type Chain struct {
+	simulated.Client
+
+	backend *simulated.Backend
+	config  Config
+	chainID *big.Int
+	keys    []*ecdsa.PrivateKey
+
+	// snapshots are the hashes of the blocks snapshots were taken at
+	snapshots []common.Hash
+
+	// node is the client of the node's own IPC endpoint in dir, for proofs
+	node *rpc.Client
+	dir  string
+}
+
+// New starts a chain, with defaults for anything left out of the config
+func New(config Config) (*Chain, error) {
+	if config.Accounts <= 0 {
+		config.Accounts = DefaultAccounts
+	}
+	if config.Balance == nil {
+		config.Balance = DefaultBalance
+	}
+	if config.GasLimit == 0 {
+		config.GasLimit = DefaultGasLimit
+	}
+
+	c := &Chain{
+		config: config,
+		keys:   make([]*ecdsa.PrivateKey, 0, config.Accounts),
+	}
+
+	alloc := make(types.GenesisAlloc)
+	for i := 0; i < config.Accounts; i++ {
+		key, err := Key(i)
+		if err != nil {
+			return nil, err
+		}
+
+		c.keys = append(c.keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: new(big.Int).Set(config.Balance)}
+	}
+
+	for address, account := range config.Alloc {
+		alloc[address] = account
+	}
+
+	options := []func(*node.Config, *ethconfig.Config){simulated.WithBlockGasLimit(config.GasLimit), withChainID(config.ChainID)}
+	if config.Proofs {
+		dir, err := ioutil.TempDir("", "wb-chain-")
+		if err != nil {
+			return nil, err
+		}
+		c.dir = dir
+
+		options = append(options, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+			nodeConf.IPCPath = filepath.Join(dir, "node.ipc")
+		})
+	}
+
+	c.backend = simulated.NewBackend(alloc, options...)
+	c.Client = c.backend.Client()
+
+	chainID, err := c.Client.ChainID(context.Background())
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.chainID = chainID
+
+	if config.Proofs {
+		if c.node, err = rpc.Dial(filepath.Join(c.dir, "node.ipc")); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// withChainID gives the genesis block a copy of the backend's chain config
+// with another chain id, leaving the shared default untouched
+func withChainID(chainID *big.Int) func(*node.Config, *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		if chainID == nil {
+			return
+		}
+
+		chainConfig := *ethConf.Genesis.Config
+		chainConfig.ChainID = new(big.Int).Set(chainID)
+		ethConf.Genesis.Config = &chainConfig
+	}
+}
+
+// FromEnv starts a chain configured by ConfigEnv, or with the defaults if it's
+// unset
+func FromEnv() (*Chain, error) {
+	var config Config
+	if env := os.Getenv(ConfigEnv); env != "" {
+		if err := json.Unmarshal([]byte(env), &config); err != nil {
+			return nil, fmt.Errorf("%s: %v", ConfigEnv, err)
+		}
+	}
+
+	return New(config)
+}
+
+// Key returns the private key of the i-th account. The keys are the same for
+// every chain, and so are public: never send them anything of value on a real
+// network
+func Key(i int) (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("wb simulated account %d", i))))
+}
+
+// Accounts returns the funded accounts
+func (c *Chain) Accounts() []common.Address {
+	accounts := make([]common.Address, 0, len(c.keys))
+	for _, key := range c.keys {
+		accounts = append(accounts, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	return accounts
+}
+
+// ChainID is the id transactions on the chain are signed for
+func (c *Chain) ChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// Transactor returns options for sending transactions from the i-th account
+func (c *Chain) Transactor(i int) (*bind.TransactOpts, error) {
+	if i < 0 || i >= len(c.keys) {
+		return nil, fmt.Errorf("No account %d, the chain has %d", i, len(c.keys))
+	}
+
+	return bind.NewKeyedTransactorWithChainID(c.keys[i], c.chainID)
+}
+
+// PrivateKey returns the key of one of the funded accounts, or nil for any
+// other address
+func (c *Chain) PrivateKey(account common.Address) *ecdsa.PrivateKey {
+	for _, key := range c.keys {
+		if crypto.PubkeyToAddress(key.PublicKey) == account {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// SendTransaction adds a transaction to the pending block, and mines it
+// straight away unless mining is manual, so bind.WaitMined and
+// bind.WaitDeployed return at once
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+
+	if !c.config.Manual {
+		c.Mine()
+	}
+
+	return nil
+}
+
+// Mine mines the pending transactions into a new block, which is empty if
+// there are none
+func (c *Chain) Mine() {
+	c.backend.Commit()
+}
+
+// Commit is Mine, for code written against go-ethereum's simulated backend
+func (c *Chain) Commit() {
+	c.Mine()
+}
+
+// Proof returns the Merkle proof of an account and some of its storage slots,
+// as eth_getProof does. Only chains configured with Proofs have them
+func (c *Chain) Proof(ctx context.Context, account common.Address, keys []string, number rpc.BlockNumber) (json.RawMessage, error) {
+	if c.node == nil {
+		return nil, errors.New("The chain doesn't serve proofs")
+	}
+
+	var proof json.RawMessage
+	err := c.node.CallContext(ctx, &proof, "eth_getProof", account, keys, number)
+	return proof, err
+}
+
+// Close stops the chain
+func (c *Chain) Close() error {
+	if c.node != nil {
+		c.node.Close()
+	}
+
+	err := c.backend.Close()
+	if c.dir != "" {
+		os.RemoveAll(c.dir)
+	}
+
+	return err
+}
+
+// Snapshot returns an id for the current state of the chain to revert to.
+// Pending transactions aren't part of it
+func (c *Chain) Snapshot() (int, error) {
+	head, err := c.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	c.snapshots = append(c.snapshots, head.Hash())
+	return len(c.snapshots) - 1, nil
+}
+
+// Revert returns the chain to the state it was in at a snapshot, dropping
+// pending transactions. The block of the snapshot is made the head again, so
+// its state is reused rather than rebuilt, and the next block mined replaces
+// the ones after it. Later snapshots are no longer valid afterwards
+func (c *Chain) Revert(snapshot int) error {
+	if snapshot < 0 || snapshot >= len(c.snapshots) {
+		return fmt.Errorf("Invalid snapshot %d", snapshot)
+	}
+
+	// Forking needs an empty pool, and the reorg it causes puts the reverted
+	// transactions back in it, so they're dropped again afterwards
+	c.backend.Rollback()
+	if err := c.backend.Fork(c.snapshots[snapshot]); err != nil {
+		return fmt.Errorf("Reverting to snapshot %d: %v", snapshot, err)
+	}
+	c.backend.Rollback()
+
+	// The snapshot itself stays valid, so it can be reverted to again
+	c.snapshots = c.snapshots[:snapshot+1]
+	return nil
+}
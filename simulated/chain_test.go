@@ -0,0 +1,104 @@
+package simulated
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// transfer signs a transfer of one wei from the first account
+func transfer(t *testing.T, c *Chain, to common.Address, nonce uint64) *types.Transaction {
+	t.Helper()
+
+	auth, err := c.Transactor(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e10)})
+	signed, err := auth.Signer(auth.From, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestRevert(t *testing.T) {
+	c, err := New(Config{Accounts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	from := c.Accounts()[0]
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := transfer(t, c, to, 0)
+	if err := c.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(when string, balance int64, nonce uint64) {
+		t.Helper()
+
+		got, err := c.BalanceAt(ctx, to, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(big.NewInt(balance)) != 0 {
+			t.Errorf("%s: balance = %v, want %d", when, got, balance)
+		}
+
+		pending, err := c.PendingNonceAt(ctx, from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pending != nonce {
+			t.Errorf("%s: pending nonce = %d, want %d", when, pending, nonce)
+		}
+	}
+	check("sent", 1, 1)
+
+	if err := c.Revert(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	check("reverted", 0, 0)
+
+	c.Mine()
+	check("mined after reverting", 0, 0)
+
+	// The reverted transaction can be sent again
+	if err := c.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("resending: %v", err)
+	}
+	check("resent", 1, 1)
+
+	// And the snapshot reverted to again
+	if err := c.Revert(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	c.Mine()
+	check("reverted twice", 0, 0)
+}
+
+func TestRevertInvalidSnapshot(t *testing.T) {
+	c, err := New(Config{Accounts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Revert(0); err == nil {
+		t.Error("Revert(0) without snapshots succeeded")
+	}
+}
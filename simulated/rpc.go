@@ -0,0 +1,574 @@
+package simulated
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ClientVersion is what the server reports as web3_clientVersion
+const ClientVersion = "wb/simulated"
+
+// Server serves a chain over Ethereum JSON-RPC, with the eth methods wallets,
+// frontends and ethclient use, along with evm_snapshot, evm_revert and
+// evm_mine for tools that reset the chain between tests
+type Server struct {
+	chain *Chain
+	rpc   *rpc.Server
+
+	// OnTransaction, if set, is called with every transaction sent through
+	// the server once the chain has accepted it
+	OnTransaction func(tx *types.Transaction)
+
+	// OnCall, if set, is called with every call and gas estimate made through
+	// the server before the chain runs it
+	OnCall func(msg ethereum.CallMsg)
+
+	// mu serializes requests, since the chain may be rewound by a revert or
+	// mined by a timer between any two of them
+	mu sync.Mutex
+}
+
+// NewServer returns a server for a chain
+func NewServer(chain *Chain) (*Server, error) {
+	s := &Server{chain: chain, rpc: rpc.NewServer()}
+
+	services := map[string]interface{}{
+		"eth":  &ethService{s},
+		"net":  &netService{s},
+		"web3": &web3Service{},
+		"evm":  &evmService{s},
+	}
+	for name, service := range services {
+		if err := s.rpc.RegisterName(name, service); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// ServeHTTP serves JSON-RPC over HTTP, to any origin so that dapps in a
+// browser can use it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.rpc.ServeHTTP(w, r)
+}
+
+// ServeListener serves JSON-RPC on connections to a listener, such as a unix
+// socket for IPC
+func (s *Server) ServeListener(l net.Listener) error {
+	return s.rpc.ServeListener(l)
+}
+
+// Mine mines the pending transactions, for chains mined on an interval
+func (s *Server) Mine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chain.Mine()
+}
+
+// Stop closes every connection
+func (s *Server) Stop() {
+	s.rpc.Stop()
+}
+
+// sendTransaction sends a transaction to the chain, and reports it
+func (s *Server) sendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := s.chain.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+
+	if s.OnTransaction != nil {
+		s.OnTransaction(tx)
+	}
+
+	return nil
+}
+
+// call reports a call about to be made
+func (s *Server) call(msg ethereum.CallMsg) {
+	if s.OnCall != nil {
+		s.OnCall(msg)
+	}
+}
+
+func (s *Server) lock() func() {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// blockNumber converts a block parameter to what the backend takes, where nil
+// is the latest block
+func blockNumber(number rpc.BlockNumber) *big.Int {
+	if number < 0 {
+		return nil
+	}
+
+	return big.NewInt(number.Int64())
+}
+
+// callArgs are the arguments of eth_call, eth_estimateGas and
+// eth_sendTransaction
+type callArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+func (args callArgs) message() ethereum.CallMsg {
+	msg := ethereum.CallMsg{From: args.From, To: args.To}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	if args.GasPrice != nil {
+		msg.GasPrice = args.GasPrice.ToInt()
+	}
+	if args.Value != nil {
+		msg.Value = args.Value.ToInt()
+	}
+
+	// Newer clients send input, older ones data
+	if args.Input != nil {
+		msg.Data = *args.Input
+	} else if args.Data != nil {
+		msg.Data = *args.Data
+	}
+
+	return msg
+}
+
+// filterArgs are the arguments of eth_getLogs
+type filterArgs struct {
+	BlockHash *common.Hash      `json:"blockHash"`
+	FromBlock *rpc.BlockNumber  `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber  `json:"toBlock"`
+	Address   json.RawMessage   `json:"address"`
+	Topics    []json.RawMessage `json:"topics"`
+}
+
+func (args filterArgs) query() (ethereum.FilterQuery, error) {
+	query := ethereum.FilterQuery{BlockHash: args.BlockHash}
+	if args.FromBlock != nil {
+		query.FromBlock = blockNumber(*args.FromBlock)
+	}
+	if args.ToBlock != nil {
+		query.ToBlock = blockNumber(*args.ToBlock)
+	}
+
+	// Addresses and each position of topics are a single value or a list
+	if len(args.Address) > 0 && string(args.Address) != "null" {
+		if err := unmarshalOneOrMany(args.Address, &query.Addresses); err != nil {
+			return query, fmt.Errorf("invalid address: %v", err)
+		}
+	}
+
+	for _, raw := range args.Topics {
+		var topics []common.Hash
+		if len(raw) > 0 && string(raw) != "null" {
+			if err := unmarshalOneOrMany(raw, &topics); err != nil {
+				return query, fmt.Errorf("invalid topic: %v", err)
+			}
+		}
+		query.Topics = append(query.Topics, topics)
+	}
+
+	return query, nil
+}
+
+func unmarshalOneOrMany(data json.RawMessage, list interface{}) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, list)
+	}
+
+	return json.Unmarshal(append(append([]byte{'['}, data...), ']'), list)
+}
+
+type ethService struct {
+	s *Server
+}
+
+func (e *ethService) ChainId() *hexutil.Big {
+	defer e.s.lock()()
+	return (*hexutil.Big)(e.s.chain.ChainID())
+}
+
+func (e *ethService) BlockNumber(ctx context.Context) (hexutil.Uint64, error) {
+	defer e.s.lock()()
+	number, err := e.s.chain.BlockNumber(ctx)
+	return hexutil.Uint64(number), err
+}
+
+func (e *ethService) Syncing() bool {
+	return false
+}
+
+func (e *ethService) Accounts() []common.Address {
+	defer e.s.lock()()
+	return e.s.chain.Accounts()
+}
+
+func (e *ethService) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	defer e.s.lock()()
+	price, err := e.s.chain.SuggestGasPrice(ctx)
+	return (*hexutil.Big)(price), err
+}
+
+func (e *ethService) GetBalance(ctx context.Context, account common.Address, number rpc.BlockNumber) (*hexutil.Big, error) {
+	defer e.s.lock()()
+	balance, err := e.s.chain.BalanceAt(ctx, account, blockNumber(number))
+	return (*hexutil.Big)(balance), err
+}
+
+func (e *ethService) GetCode(ctx context.Context, account common.Address, number rpc.BlockNumber) (hexutil.Bytes, error) {
+	defer e.s.lock()()
+	if number == rpc.PendingBlockNumber {
+		return e.s.chain.PendingCodeAt(ctx, account)
+	}
+
+	return e.s.chain.CodeAt(ctx, account, blockNumber(number))
+}
+
+func (e *ethService) GetStorageAt(ctx context.Context, account common.Address, key common.Hash, number rpc.BlockNumber) (hexutil.Bytes, error) {
+	defer e.s.lock()()
+	return e.s.chain.StorageAt(ctx, account, key, blockNumber(number))
+}
+
+func (e *ethService) GetProof(ctx context.Context, account common.Address, keys []string, number rpc.BlockNumber) (json.RawMessage, error) {
+	defer e.s.lock()()
+	return e.s.chain.Proof(ctx, account, keys, number)
+}
+
+func (e *ethService) GetTransactionCount(ctx context.Context, account common.Address, number rpc.BlockNumber) (hexutil.Uint64, error) {
+	defer e.s.lock()()
+	if number == rpc.PendingBlockNumber {
+		nonce, err := e.s.chain.PendingNonceAt(ctx, account)
+		return hexutil.Uint64(nonce), err
+	}
+
+	nonce, err := e.s.chain.NonceAt(ctx, account, blockNumber(number))
+	return hexutil.Uint64(nonce), err
+}
+
+func (e *ethService) Call(ctx context.Context, args callArgs, number rpc.BlockNumber) (hexutil.Bytes, error) {
+	defer e.s.lock()()
+	e.s.call(args.message())
+	if number == rpc.PendingBlockNumber {
+		return e.s.chain.PendingCallContract(ctx, args.message())
+	}
+
+	return e.s.chain.CallContract(ctx, args.message(), blockNumber(number))
+}
+
+func (e *ethService) EstimateGas(ctx context.Context, args callArgs) (hexutil.Uint64, error) {
+	defer e.s.lock()()
+	e.s.call(args.message())
+	gas, err := e.s.chain.EstimateGas(ctx, args.message())
+	return hexutil.Uint64(gas), err
+}
+
+func (e *ethService) SendRawTransaction(ctx context.Context, data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+
+	defer e.s.lock()()
+	if err := e.s.sendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+
+	return tx.Hash(), nil
+}
+
+// SendTransaction signs a transaction from one of the funded accounts, filling
+// in anything left out, and sends it
+func (e *ethService) SendTransaction(ctx context.Context, args callArgs) (common.Hash, error) {
+	defer e.s.lock()()
+
+	key := e.s.chain.PrivateKey(args.From)
+	if key == nil {
+		return common.Hash{}, fmt.Errorf("unknown account %s", args.From.Hex())
+	}
+
+	msg := args.message()
+	if msg.Value == nil {
+		msg.Value = new(big.Int)
+	}
+
+	nonce, err := e.s.chain.PendingNonceAt(ctx, args.From)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	}
+
+	if msg.GasPrice == nil {
+		if msg.GasPrice, err = e.s.chain.SuggestGasPrice(ctx); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	if msg.Gas == 0 {
+		if msg.Gas, err = e.s.chain.EstimateGas(ctx, msg); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: msg.GasPrice,
+		Gas:      msg.Gas,
+		To:       msg.To,
+		Value:    msg.Value,
+		Data:     msg.Data,
+	}), types.LatestSignerForChainID(e.s.chain.ChainID()), key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := e.s.sendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+
+	return tx.Hash(), nil
+}
+
+func (e *ethService) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	defer e.s.lock()()
+
+	tx, pending, err := e.s.chain.TransactionByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *types.Receipt
+	if !pending {
+		if receipt, err = e.s.chain.TransactionReceipt(ctx, hash); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.s.marshalTransaction(tx, receipt)
+}
+
+func (e *ethService) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	defer e.s.lock()()
+
+	receipt, err := e.s.chain.TransactionReceipt(ctx, hash)
+	if err != nil && err != ethereum.NotFound {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+
+	tx, _, err := e.s.chain.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := toFields(receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(e.s.chain.ChainID()), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields["from"] = from
+	fields["to"] = tx.To()
+	fields["effectiveGasPrice"] = (*hexutil.Big)(tx.GasPrice())
+	if tx.To() != nil {
+		fields["contractAddress"] = nil
+	}
+
+	return fields, nil
+}
+
+func (e *ethService) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	defer e.s.lock()()
+
+	block, err := e.s.chain.BlockByNumber(ctx, blockNumber(number))
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e.s.marshalBlock(block, full)
+}
+
+func (e *ethService) GetBlockByHash(ctx context.Context, hash common.Hash, full bool) (map[string]interface{}, error) {
+	defer e.s.lock()()
+
+	block, err := e.s.chain.BlockByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e.s.marshalBlock(block, full)
+}
+
+func (e *ethService) GetLogs(ctx context.Context, args filterArgs) ([]types.Log, error) {
+	query, err := args.query()
+	if err != nil {
+		return nil, err
+	}
+
+	defer e.s.lock()()
+	logs, err := e.s.chain.FilterLogs(ctx, query)
+	if logs == nil {
+		logs = make([]types.Log, 0)
+	}
+
+	return logs, err
+}
+
+// marshalTransaction is a transaction as JSON-RPC returns it, with where it
+// was mined if it has been
+func (s *Server) marshalTransaction(tx *types.Transaction, receipt *types.Receipt) (map[string]interface{}, error) {
+	fields, err := toFields(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(s.chain.ChainID()), tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = from
+
+	fields["blockHash"], fields["blockNumber"], fields["transactionIndex"] = nil, nil, nil
+	if receipt != nil {
+		fields["blockHash"] = receipt.BlockHash
+		fields["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+		fields["transactionIndex"] = hexutil.Uint(receipt.TransactionIndex)
+	}
+
+	return fields, nil
+}
+
+// marshalBlock is a block as JSON-RPC returns it, with the hashes of its
+// transactions or, if full, the transactions themselves
+func (s *Server) marshalBlock(block *types.Block, full bool) (map[string]interface{}, error) {
+	fields, err := toFields(block.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	fields["hash"] = block.Hash()
+	fields["size"] = hexutil.Uint64(block.Size())
+	fields["uncles"] = make([]common.Hash, 0)
+
+	transactions := make([]interface{}, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if !full {
+			transactions = append(transactions, tx.Hash())
+			continue
+		}
+
+		receipt := &types.Receipt{BlockHash: block.Hash(), BlockNumber: block.Number(), TransactionIndex: uint(i)}
+		marshaled, err := s.marshalTransaction(tx, receipt)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, marshaled)
+	}
+	fields["transactions"] = transactions
+
+	return fields, nil
+}
+
+// toFields turns a value into its JSON fields, so more can be added
+func toFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+type netService struct {
+	s *Server
+}
+
+// Version is the network id, which is the chain id
+func (n *netService) Version() string {
+	defer n.s.lock()()
+	return n.s.chain.ChainID().String()
+}
+
+func (n *netService) Listening() bool {
+	return true
+}
+
+type web3Service struct{}
+
+func (w *web3Service) ClientVersion() string {
+	return ClientVersion
+}
+
+// evmService has the methods of Ganache and Hardhat for controlling the chain
+type evmService struct {
+	s *Server
+}
+
+func (e *evmService) Snapshot() (hexutil.Uint64, error) {
+	defer e.s.lock()()
+	snapshot, err := e.s.chain.Snapshot()
+	return hexutil.Uint64(snapshot), err
+}
+
+func (e *evmService) Revert(snapshot hexutil.Uint64) (bool, error) {
+	defer e.s.lock()()
+	if err := e.s.chain.Revert(int(snapshot)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (e *evmService) Mine() string {
+	defer e.s.lock()()
+	e.s.chain.Mine()
+	return "0x0"
+}
@@ -0,0 +1,287 @@
+package simulated
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// serve starts a chain behind a server over HTTP, returning clients for it
+func serve(t *testing.T) (*Chain, *ethclient.Client, *rpc.Client) {
+	t.Helper()
+
+	chain, err := New(Config{Accounts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { chain.Close() })
+
+	server, err := NewServer(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	http := httptest.NewServer(server)
+	t.Cleanup(http.Close)
+
+	client, err := rpc.Dial(http.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	return chain, ethclient.NewClient(client), client
+}
+
+// logTopic is what logConstructor logs
+var logTopic = common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000abc")
+
+// logConstructor is creation code logging 42 under logTopic, leaving no code
+const logConstructor = "0x602a600052" + "7f0000000000000000000000000000000000000000000000000000000000000abc" + "60206000a1" + "00"
+
+func TestServerSendTransaction(t *testing.T) {
+	chain, client, raw := serve(t)
+	ctx := context.Background()
+	from, to := chain.Accounts()[0], chain.Accounts()[1]
+
+	// Everything but the sender and recipient is filled in
+	var hash common.Hash
+	err := raw.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"value": (*hexutil.Big)(big.NewInt(1000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, pending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending {
+		t.Error("transaction is pending, want it mined")
+	}
+	if tx.Nonce() != 0 || tx.Gas() != 21000 || tx.GasPrice().Sign() <= 0 {
+		t.Errorf("nonce, gas, gas price = %d, %d, %v, want 0, 21000 and a price", tx.Nonce(), tx.Gas(), tx.GasPrice())
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("status = %d, want success", receipt.Status)
+	}
+
+	var fields map[string]interface{}
+	if err := raw.CallContext(ctx, &fields, "eth_getTransactionReceipt", hash); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"transactionHash", "blockHash", "blockNumber", "transactionIndex", "from", "to", "gasUsed", "cumulativeGasUsed", "effectiveGasPrice", "contractAddress", "logs", "logsBloom", "status"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("receipt has no %s", field)
+		}
+	}
+	if fields["from"] != hexutil.Encode(from[:]) || fields["to"] != hexutil.Encode(to[:]) || fields["contractAddress"] != nil {
+		t.Errorf("from, to, contractAddress = %v, %v, %v, want %s, %s, null", fields["from"], fields["to"], fields["contractAddress"], from.Hex(), to.Hex())
+	}
+
+	// Unknown senders and transactions
+	err = raw.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{"from": common.HexToAddress("0x1"), "to": to})
+	if err == nil {
+		t.Error("sending from an unknown account succeeded")
+	}
+	if _, err := client.TransactionReceipt(ctx, common.HexToHash("0x1")); err != ethereum.NotFound {
+		t.Errorf("receipt of an unknown transaction: %v, want not found", err)
+	}
+}
+
+func TestServerBlocks(t *testing.T) {
+	chain, client, raw := serve(t)
+	ctx := context.Background()
+
+	var hash common.Hash
+	err := raw.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{"from": chain.Accounts()[0], "to": chain.Accounts()[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := client.BlockByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != hash {
+		t.Errorf("latest block has %d transactions, want the one sent", len(block.Transactions()))
+	}
+
+	byHash, err := client.BlockByHash(ctx, block.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byHash.Hash() != block.Hash() {
+		t.Errorf("block by hash = %s, want %s", byHash.Hash().Hex(), block.Hash().Hex())
+	}
+
+	// Blocks that don't exist are null rather than errors
+	if _, err := client.BlockByNumber(ctx, big.NewInt(1000)); err != ethereum.NotFound {
+		t.Errorf("block 1000: %v, want not found", err)
+	}
+	if _, err := client.BlockByHash(ctx, common.HexToHash("0x1")); err != ethereum.NotFound {
+		t.Errorf("unknown block: %v, want not found", err)
+	}
+}
+
+func TestServerLogs(t *testing.T) {
+	chain, client, raw := serve(t)
+	ctx := context.Background()
+
+	var hash common.Hash
+	err := raw.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{"from": chain.Accounts()[0], "data": logConstructor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := receipt.ContractAddress
+
+	// Addresses and topics may each be one value or a list
+	filters := []struct {
+		name   string
+		filter map[string]interface{}
+		want   int
+	}{
+		{"address", map[string]interface{}{"fromBlock": "0x0", "address": address}, 1},
+		{"addresses", map[string]interface{}{"fromBlock": "0x0", "address": []common.Address{address}}, 1},
+		{"topic", map[string]interface{}{"fromBlock": "0x0", "topics": []interface{}{logTopic}}, 1},
+		{"topics", map[string]interface{}{"fromBlock": "0x0", "topics": []interface{}{[]common.Hash{logTopic}}}, 1},
+		{"any topic", map[string]interface{}{"fromBlock": "0x0", "topics": []interface{}{nil}}, 1},
+		{"other address", map[string]interface{}{"fromBlock": "0x0", "address": common.HexToAddress("0x1")}, 0},
+		{"other topic", map[string]interface{}{"fromBlock": "0x0", "topics": []interface{}{common.HexToHash("0x1")}}, 0},
+	}
+
+	for _, test := range filters {
+		var logs []types.Log
+		if err := raw.CallContext(ctx, &logs, "eth_getLogs", test.filter); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if len(logs) != test.want {
+			t.Errorf("%s: %d logs, want %d", test.name, len(logs), test.want)
+		}
+		for _, log := range logs {
+			if log.Address != address || !reflect.DeepEqual(log.Topics, []common.Hash{logTopic}) {
+				t.Errorf("%s: log from %s with topics %v, want %s with %s", test.name, log.Address.Hex(), log.Topics, address.Hex(), logTopic.Hex())
+			}
+		}
+	}
+
+	var logs []types.Log
+	if err := raw.CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{"address": "not an address"}); err == nil {
+		t.Error("invalid address succeeded")
+	}
+}
+
+func TestFilterArgsQuery(t *testing.T) {
+	address := common.HexToAddress("0xaa")
+	topic := common.HexToHash("0xbb")
+
+	tests := []struct {
+		args string
+		want ethereum.FilterQuery
+	}{
+		{`{}`, ethereum.FilterQuery{}},
+		{`{"fromBlock":"0x5","toBlock":"latest"}`, ethereum.FilterQuery{FromBlock: big.NewInt(5)}},
+		{`{"address":"0x00000000000000000000000000000000000000aa"}`, ethereum.FilterQuery{Addresses: []common.Address{address}}},
+		{`{"address":["0x00000000000000000000000000000000000000aa"]}`, ethereum.FilterQuery{Addresses: []common.Address{address}}},
+		{`{"address":null}`, ethereum.FilterQuery{}},
+		{`{"topics":[null,"0x00000000000000000000000000000000000000000000000000000000000000bb"]}`, ethereum.FilterQuery{Topics: [][]common.Hash{nil, {topic}}}},
+		{`{"topics":[["0x00000000000000000000000000000000000000000000000000000000000000bb"]]}`, ethereum.FilterQuery{Topics: [][]common.Hash{{topic}}}},
+	}
+
+	for _, test := range tests {
+		var args filterArgs
+		if err := json.Unmarshal([]byte(test.args), &args); err != nil {
+			t.Fatalf("%s: %v", test.args, err)
+		}
+
+		got, err := args.query()
+		if err != nil {
+			t.Errorf("%s: %v", test.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: query() = %+v, want %+v", test.args, got, test.want)
+		}
+	}
+}
+
+func TestServerSnapshotRevert(t *testing.T) {
+	chain, client, raw := serve(t)
+	ctx := context.Background()
+	from, to := chain.Accounts()[0], chain.Accounts()[1]
+
+	before, err := client.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var snapshot hexutil.Uint64
+	if err := raw.CallContext(ctx, &snapshot, "evm_snapshot"); err != nil {
+		t.Fatal(err)
+	}
+
+	var hash common.Hash
+	err = raw.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{"from": from, "to": to, "value": "0x1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reverted bool
+	if err := raw.CallContext(ctx, &reverted, "evm_revert", snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if !reverted {
+		t.Error("evm_revert returned false")
+	}
+
+	var mined string
+	if err := raw.CallContext(ctx, &mined, "evm_mine"); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := client.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Cmp(before) != 0 {
+		t.Errorf("balance after reverting = %v, want %v", after, before)
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce != 0 {
+		t.Errorf("pending nonce after reverting = %d, want 0", nonce)
+	}
+
+	if err := raw.CallContext(ctx, &reverted, "evm_revert", hexutil.Uint64(7)); err == nil {
+		t.Error("reverting to an unknown snapshot succeeded")
+	}
+}
@@ -443,7 +443,7 @@ func licensesMitTextTpl() (*asset, error) {
 	return a, nil
 }
 
-var _migrationMigrationGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x54\xbf\x6e\xdb\x3c\x10\x9f\xc5\xa7\xe0\xa7\x21\x90\x0c\x7d\x52\x67\x15\x19\xd2\x14\xdd\xea\x06\x68\x8b\x0e\x45\x07\x9a\xbe\xc8\x6c\x24\x52\x38\x9e\xe0\x04\x82\xde\xbd\x20\x45\xca\xb1\xeb\x22\xf1\xd6\x2e\x12\x49\x1c\xef\x7e\xff\xc0\x5e\xc8\x07\xd1\x00\xef\x54\x83\x82\x94\xd1\x96\x31\xd5\xf5\x06\x89\x67\x2c\x49\xa5\xd1\x04\x8f\x94\x32\x96\xa4\x8d\xa2\xdd\xb0\x29\xa5\xe9\x2a\xa0\x1d\x20\x0c\x5d\xd5\x98\xff\x97\xb5\x90\xd2\x0c\x9a\x6c\x25\x36\xaa\xda\x28\xbd\x4d\x5f\x71\x49\x9a\xae\x33\xfa\x75\x95\x08\x15\x3d\xf5\x60\x4f\xd1\xf4\xa6\x7d\xb2\x7b\x81\x5d\xd5\x03\xaa\xc6\xe0\xb6\x72\xb8\x51\x48\x4a\x5f\xac\x5c\x88\xbf\x5c\xaa\x81\xf6\x06\x1f\xfc\xf8\x71\x2c\x7b\x34\x3f\x41\xd2\x34\x79\xb2\x4a\x37\x36\x65\x39\x63\x0e\x22\x1f\xc7\x32\x42\x98\xa6\xf7\xd0\xb7\xe6\x09\x90\x5b\xc2\x41\xd2\x38\x31\x76\x3f\x68\xc9\xb3\x2d\x5f\x9d\x2d\xcc\xf9\xbc\xca\x24\x3d\xf2\x60\x41\x79\x3b\xff\x0b\x1e\x60\xf0\x55\x58\x94\xeb\xf9\x9f\xf3\x6c\x56\xb3\xbc\xd9\x6e\x11\xac\x2d\xf8\xca\xeb\x55\x7e\x41\xa1\xad\x90\x8e\x65\xc1\x95\x26\xc0\x7b\x21\x61\x9c\x0a\x0e\x88\x06\x73\x3e\xb2\x24\xb8\xc7\xeb\xeb\x38\xa0\xbc\x09\x86\x66\xf9\xf7\x37\x3f\x58\x12\x8f\xbf\xea\xd6\xc8\x87\x6f\x8a\x76\x77\x68\xba\x9e\xb2\x70\x35\x67\x2c\x11\x03\xed\x9e\xb7\x58\xc3\x3e\x0e\x37\x78\x28\x4c\x44\x44\x48\xcf\xa1\x45\x25\x3c\x2e\xd7\x26\x0a\x5b\xce\x7a\x1c\x89\x95\xb9\x59\x8b\x1a\xe5\x6d\xab\x40\x53\x96\xe7\x2c\x51\xf7\xfe\xfe\x7f\xd7\x5c\xab\xd6\x71\x4b\x10\x68\x40\xcd\x8f\xe5\x71\xfc\xb5\x6a\xc3\x07\x10\x59\x32\x31\x96\x58\xb0\x56\x19\xed\xc6\x5f\x2d\xf3\x8f\x26\x7f\x9e\x2b\x5c\xe3\xdb\x70\x58\x1f\xb0\xbb\x53\xd1\xb6\x9f\x7a\xb2\xb5\x27\x50\xc6\xad\xbb\x90\xdc\x81\x6f\x59\x73\xc2\x01\x5c\xf1\xe4\x3e\x51\xa4\xf9\xd6\xca\x53\x9b\xe1\x04\xe8\xe7\x05\x0b\x58\x3d\x05\xf6\x8a\x58\xbd\x53\x7a\x7b\x59\xa8\x8a\x38\xf9\x44\xbc\x9c\x67\x7f\x45\x90\xfe\x1c\x99\x35\xec\x4f\xf2\x12\x25\xbc\x24\x32\xff\x42\x36\xce\xa6\x40\x69\x45\x99\x37\x24\x4e\x77\xd6\x45\x4c\x59\x7a\x84\x3a\x2d\xf8\xd5\xd9\xc8\x8c\x93\x33\x63\x79\x23\x5d\x8b\x8f\x71\x93\x5d\x1d\xce\x97\x43\xc7\x63\x3d\x74\x1b\xc0\xda\x3d\x82\xda\x2f\x27\xcf\xe3\x43\xcd\x1d\xb2\x4b\x1f\x35\x9f\x2c\x6f\x49\x74\xa9\xbe\x5e\x14\x2d\x0f\x0f\x65\xc1\x7f\xe3\x14\x7a\xe5\x6f\x4f\xcd\x5d\xec\xf5\xce\x26\x5e\xcc\xe7\x96\x07\xe9\xa7\x9c\x4d\xec\x57\x00\x00\x00\xff\xff\x65\x01\xd9\xd9\x22\x07\x00\x00"
+var _migrationMigrationGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd5\x56\xcf\x6f\xd3\x30\x14\x3e\xc7\x7f\xc5\x23\x87\x91\x56\x21\xb9\x20\x0e\x45\x3b\xb0\x21\xb4\x03\x1b\x08\x86\x38\x22\x37\x71\x5b\xab\xa9\x1d\xd9\x8e\xb6\x51\xe5\x7f\xe7\xd9\xb1\xd3\xa4\x2b\x6a\xa7\x8a\x03\x3b\xac\xae\xfd\x7e\x7d\xdf\xf7\xde\x53\x6b\x5a\xac\xe9\x92\xc1\x86\x2f\x15\x35\x5c\x0a\x4d\x08\xdf\xd4\x52\x19\x48\x48\x14\x17\x52\x18\xf6\x68\x62\x82\xe7\x25\x37\xab\x66\x9e\x15\x72\x93\x33\xb3\x62\x8a\x35\x9b\x7c\x29\xdf\xf4\x67\x5a\x14\xb2\x11\x46\xe7\x74\xce\xf3\x39\x17\x65\x7c\x82\x13\x3e\x6c\xa4\x38\xcd\x52\xb1\xdc\x3c\xd5\x4c\xef\x57\x53\xcb\xea\x49\x3f\x50\x85\x27\xa6\xf8\x52\xaa\x32\xb7\x75\x2b\x5a\x98\xf8\xa8\x65\x0f\xfc\xb8\xa9\x60\xe6\x41\xaa\xf5\x9e\xe1\x6f\x5d\xc8\x8a\xe5\x45\xc5\xf3\x92\xd5\x95\x7c\x62\xea\x98\xc5\x86\x21\x4d\x0e\xc5\x76\x9b\x59\xa6\xb8\x58\xea\xb6\x8d\xc9\x84\x10\x8b\x10\xf0\x3a\x20\x68\xdb\x8f\x3e\x2a\x68\xa3\x9a\xc2\x6c\x5b\x42\x16\x8d\x28\x20\x29\x61\x7a\xd0\x70\x02\xdd\x29\x29\xcc\x23\x78\x05\xb3\xeb\xee\x33\x05\x8f\x02\xa6\xfe\x90\xdd\x75\x9f\x13\x48\x3a\x31\xb2\x0f\x65\xa9\x98\xd6\x29\x4c\x1d\xdd\xd9\xbd\xa2\x42\x63\x06\x24\x29\x05\x8e\x61\xd4\x82\x16\x6c\xdb\xa6\xc0\x94\x92\x98\x6e\x4b\x22\x2f\xbe\xbb\x82\xd9\x25\x04\x2a\xb2\x1f\xa2\x92\xc5\x3a\xf1\xc9\x26\x24\xe2\x0b\x67\xf3\xea\x12\x04\xaf\xac\x6b\xa4\x98\x69\x94\x80\x71\x76\x1b\x1e\x0d\xfc\x3f\xf4\x20\x11\x02\x8f\xf2\x1c\xee\x57\x0c\x3c\x69\xaf\x35\xcc\x9f\x0c\x2b\x64\xc9\x80\x6b\x68\x44\xc5\xc5\x9a\x95\x29\x68\x09\x81\x17\x0d\x66\x45\x0d\x34\x9a\x41\xc5\xe7\x8a\x2a\xce\xb4\x8b\x43\x15\x0b\x65\x96\x40\x35\x74\xbe\xb0\x90\x0a\x3d\x30\x9a\x2f\x99\x44\x21\xe8\x08\x9a\xd3\x30\xfb\xec\x9e\xae\x7c\x0d\x49\x3c\xd2\x23\x3e\x13\xad\x75\xf6\x45\xa1\x7f\x1c\x3b\xf7\x41\xc7\xfc\xaa\xbb\xe1\x6d\xdb\x0c\x6f\xad\x56\x6d\x7b\xc5\x05\x5c\x7a\xaf\x8e\x31\xda\x98\x95\x2d\x7b\x27\xf7\x43\x10\x54\xaa\xc4\xeb\x86\x95\xd2\xa0\xba\x19\xca\x1d\xd0\xf4\xe8\x0f\xe7\xef\x1a\xae\xaf\x22\xb1\x49\xfb\x56\xcb\xae\x2b\x8e\x6c\x25\x93\xb3\xd5\x57\xac\x60\xbc\xde\x15\x63\x2b\xc9\x7e\x52\x6e\x6e\xb9\x60\xa5\xed\xf7\xe7\x49\x47\x80\xce\xae\xc0\xba\x8e\x5b\xe0\x1b\x4a\xaf\xca\x6b\xcf\xd3\x7e\x0b\xa4\x23\xdb\x60\x65\xb3\xfa\x3c\x33\x70\x7f\x9e\xfd\xec\x86\x3d\x62\xc9\xf8\x3c\x18\x3a\x34\x19\x40\xc8\x6e\xa8\x5e\x25\x93\x9d\xe5\x95\x1d\x30\x1f\x06\xc0\x53\x94\xb9\xdb\xbb\x66\x33\xb7\x33\x88\x43\xfb\xee\xad\xb7\xf6\xbd\x6a\xa3\xcc\x46\xc5\x0d\x5f\x92\xe3\x6d\xe6\xa2\xdd\x86\xed\xe9\xf2\xe3\xb3\x70\x19\xdb\x16\x1f\xdb\xb3\xc9\xd6\xf8\x84\xb1\xad\xd2\x17\x47\x0a\xfa\xde\x99\xda\x0c\x81\xe4\xd9\xae\x79\xed\x2d\xad\xaa\x2f\xb5\x41\xbe\x5d\xd3\x84\xaf\xd6\x21\xfa\xca\x5c\xe0\x19\xd2\xdc\x30\x6b\xdc\x0e\x15\xe8\xbc\xa6\xae\xa5\x43\x1b\x3a\x0c\x87\x27\xc6\x17\xed\xb0\x90\x13\x76\x35\x52\x59\xbe\x6c\x53\xa7\x21\xf3\x1e\x8b\xb8\xc1\xff\xdd\x76\x1e\x2b\x73\xea\x52\x39\x71\x7d\xa0\xfb\x60\x77\x04\x5a\x5f\xb2\x3e\xfe\xab\xc6\x39\xd8\x22\x5c\x70\xc4\x68\x31\x85\xec\x56\xd7\xbf\xef\x95\x8b\x83\xfd\xb4\xc5\xa9\x23\x51\xff\xa3\xc6\x86\xe8\x67\x34\xb9\xd8\xdd\xf7\x97\x16\x47\xb7\x26\x66\x7b\xf3\x1b\x7d\x9a\x81\xad\xec\xa5\x3f\x23\x5c\xdb\x39\x6d\x82\x5c\x28\x43\x8f\x69\xf7\xd3\x24\x85\x67\x98\x42\x1f\xbe\xdf\x57\xb9\xd7\xd9\x49\x1c\x39\x32\xfb\xbb\xe7\xdb\x78\x07\x79\x00\x69\xe2\xf5\xc1\x43\x4b\xfe\x00\x16\x42\x30\x60\xf8\x0a\x00\x00"
 
 func migrationMigrationGoTplBytes() ([]byte, error) {
 	return bindataRead(
@@ -458,7 +458,7 @@ func migrationMigrationGoTpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "migration/migration.go.tpl", size: 1826, mode: os.FileMode(436), modTime: time.Unix(1517899410, 0)}
+	info := bindataFileInfo{name: "migration/migration.go.tpl", size: 2808, mode: os.FileMode(436), modTime: time.Unix(1792325556, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -543,46 +543,6 @@ func projectMainGoTpl() (*asset, error) {
 	return a, nil
 }
 
-var _projectMigrations1_migrationsGo = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x54\x3d\x6f\xdb\x30\x10\x9d\xc5\x5f\x71\xd5\x10\x48\x86\x2a\xb5\xab\x8a\x0c\x69\x8a\x6e\x75\x03\xb4\x45\x87\xa2\x03\x4d\x5f\x64\x22\x12\x29\x1c\x29\x38\x81\xa1\xff\x5e\x90\x22\x25\xe7\xa3\x70\xbc\x65\x91\x64\xfa\xee\xde\xbb\xf7\x1e\xd8\x73\x71\xc7\x1b\x84\x4e\x36\xc4\xad\xd4\xca\x30\x26\xbb\x5e\x93\x85\x8c\x25\xa9\xd0\xca\xe2\xbd\x4d\x19\x4b\xd2\x46\xda\xdd\xb0\x29\x85\xee\x2a\xb4\x3b\x24\x1c\xba\xaa\xd1\xef\xe7\x6f\x2e\x84\x1e\x94\x35\x15\xdf\xc8\x6a\x23\xd5\x36\x7d\x45\x93\xd0\x5d\xa7\xd5\xeb\x2a\x09\x2b\xfb\xd0\xa3\x79\xca\xa6\xd7\xed\x83\xd9\x73\xea\xaa\x1e\x49\x36\x9a\xb6\x95\xe3\x4d\x5c\xd8\xf4\x64\xe5\xbc\xf8\x19\xa5\x7e\x3b\xa9\x1a\x73\xba\x47\xa1\xdd\x6b\xba\x4b\x59\xce\x98\x23\x0f\xdf\x66\xa1\xbf\x60\xdf\xea\x07\x24\x30\x96\x06\x61\x0f\x23\x63\xb7\x83\x12\x90\x6d\x61\xf5\xbc\x2a\x87\xe9\x2b\x13\xf6\x1e\x82\x2d\xe5\xf5\xf4\x2e\x20\xc0\xc0\x2a\x7c\x94\xeb\xe9\x9d\x43\x36\x29\x5c\x5e\x6d\xb7\x84\xc6\x14\xb0\xf2\x1a\x96\x3f\x89\x2b\xc3\x85\xc3\x28\x40\x2a\x8b\x74\xcb\x05\x1e\xc6\x02\x90\x48\x53\x0e\x07\x96\x04\x47\xa1\xbe\x8c\x00\xe5\x55\x30\x39\xcb\xff\x7c\xf8\xcb\x92\x78\xfc\x4b\xb5\x5a\xdc\xfd\x96\x76\x77\x43\xba\xeb\x6d\x16\x5a\x73\xc6\x12\x3e\xd8\xdd\xf1\x88\x35\xee\x23\xb8\xa6\xa5\x30\xe1\x91\xa1\x3d\xa6\x16\x9d\xf4\xbc\xdc\x98\xa8\x7d\x39\xe9\xb1\x28\x95\x39\xa0\x59\x8a\xf2\xba\x95\xa8\x6c\x96\xe7\x2c\x91\xb7\xbe\xf9\xdd\x25\x28\xd9\xba\xc5\x12\x42\x3b\x90\x82\xc7\xda\xb8\xe5\x95\x6c\xc3\x03\x89\x58\x32\x32\x96\x18\x34\x46\x6a\xe5\xb0\x2f\x66\xf0\x05\xf6\xc7\xf4\xb7\x9b\x7a\x1d\xb8\xd6\x0b\x6b\x77\xca\xdb\xf6\x7b\x6f\x4d\xed\xa9\x97\xf1\xa7\x6b\x48\x6e\xd0\xcf\xab\xc1\xd2\x80\xae\x78\x74\x8f\x28\xcf\xd4\xb5\xf2\x7b\x4d\x5c\x02\xef\x97\xa5\x0a\x44\x3d\x7f\x76\x2a\x4d\x9f\xa5\xda\x9e\x97\xa5\x22\xc2\x3e\x91\x2d\x87\xec\x4d\xe4\xe7\xff\x49\x59\xe3\xfe\x38\x26\x51\xbc\x73\x92\xf2\xe6\x23\xf1\xa2\xf9\x52\x49\x9b\x79\x2b\x22\xba\x33\x2d\x72\xca\xd2\x85\x72\x5a\xc0\xc5\xf3\x98\x1c\x46\x67\xc0\x7c\xef\xb9\xe6\xb9\x28\xbb\x58\xce\xe7\x43\xb7\xc1\x7a\xe8\x36\x48\x35\x7c\x74\xbc\xbf\xd6\xe0\x98\x9c\x7b\x6b\xf9\x0c\x79\xfd\xa3\x25\xf5\xe5\xac\x60\xb9\xdc\x84\x05\x3c\xde\x21\x0c\xca\x3f\x3d\xb5\x71\x36\xd2\x7b\x98\x78\xe5\x8e\xcd\x0d\x3a\x8f\x39\x1b\xd9\xbf\x00\x00\x00\xff\xff\xdb\x89\xfa\xc9\x14\x07\x00\x00"
-
-func projectMigrations1_migrationsGoBytes() ([]byte, error) {
-	return bindataRead(
-		_projectMigrations1_migrationsGo,
-		"project/migrations/1_Migrations.go",
-	)
-}
-
-func projectMigrations1_migrationsGo() (*asset, error) {
-	bytes, err := projectMigrations1_migrationsGoBytes()
-	if err != nil {
-		return nil, err
-	}
-
-	info := bindataFileInfo{name: "project/migrations/1_Migrations.go", size: 1812, mode: os.FileMode(436), modTime: time.Unix(1517899410, 0)}
-	a := &asset{bytes: bytes, info: info}
-	return a, nil
-}
-
-var _projectCliYamlTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8b\xc1\x0d\xc2\x30\x10\x04\xff\x57\xc5\x55\xe0\xfc\x5d\x04\x2d\x44\x91\x59\x81\x49\x62\x5b\xe7\x25\x08\x45\xee\x1d\x11\x0c\x4f\xf6\x35\x1a\xcd\x16\xcb\x37\x04\x7a\xdd\x77\xd7\xb9\x35\x59\x62\x40\xaa\x38\x6c\x67\x77\x9a\x56\xb4\x26\x92\xc0\x47\xb6\xb9\x7a\x51\x55\x3d\x63\xfb\xc0\x7b\x77\x5b\xbc\x0e\x5c\xcb\x70\x01\xaf\x63\xb1\xb8\x4d\xc4\x48\x54\x26\xf0\x90\x2e\x96\xf0\xeb\x67\x3c\x2b\xb3\xe1\xdf\xe9\xdb\xc8\x2b\x00\x00\xff\xff\xe5\x6e\xe0\x66\xaa\x00\x00\x00"
-
-func projectCliYamlTplBytes() ([]byte, error) {
-	return bindataRead(
-		_projectCliYamlTpl,
-		"project/cli.yaml.tpl",
-	)
-}
-
-func projectCliYamlTpl() (*asset, error) {
-	bytes, err := projectCliYamlTplBytes()
-	if err != nil {
-		return nil, err
-	}
-
-	info := bindataFileInfo{name: "project/cli.yaml.tpl", size: 170, mode: os.FileMode(436), modTime: time.Unix(1517899410, 0)}
-	a := &asset{bytes: bytes, info: info}
-	return a, nil
-}
-
 var _projectStubReadmeMdTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xd0\x41\x4e\x43\x31\x0c\x04\xd0\x7d\x4e\x31\x12\xbb\x0a\xb5\x57\x61\x01\x6b\x94\xfe\xb8\x3f\x86\x24\x8e\x6c\xa7\x55\x6e\x8f\x52\xbe\x10\x7b\xcf\xd3\x8c\x5f\xf0\x46\xca\xbb\x68\x82\xf9\xb8\x86\xf0\x9e\xd9\x70\xe3\x42\xf8\x1a\xe6\x30\xd2\x3b\x19\xa2\x21\x36\x50\x73\x9d\xe8\xc2\xcd\x71\x13\x85\x67\x0a\x3b\x7b\x1e\xd7\xf3\x26\xf5\xd2\xa5\x4c\x7b\x44\xad\x97\x7e\x98\x97\x65\x62\x93\x5a\x63\x4b\xaf\x78\x64\xde\xf2\x7f\x12\x49\xf9\x4e\xba\xb0\xd0\xff\x7a\x8c\xeb\x91\x30\xd8\xd8\xf2\xba\x74\x32\x47\x6c\x09\x89\x7a\x91\x79\x48\x75\x35\x2c\xdc\xbe\x11\xf7\xc8\xcd\x1c\x53\x86\x86\xca\xbb\x46\x67\x69\xf6\x8c\xac\xac\x9d\x43\x38\x7d\x18\xa9\xc1\xb2\x8c\x92\xd0\xc4\xd1\x88\x12\x5c\x50\x25\xf1\x6d\x3e\x57\x1b\xb8\xc1\xd7\x0f\x12\x2b\x6d\x2e\x3a\xf1\xbb\x14\x2e\x1d\x85\xee\x54\xc2\x5a\xf5\xb9\xd8\xf3\x2e\xa7\xf0\x13\x00\x00\xff\xff\xe0\x71\x04\x56\x43\x01\x00\x00"
 
 func projectStubReadmeMdTplBytes() ([]byte, error) {
@@ -663,7 +623,7 @@ func projectTestsFooGoTpl() (*asset, error) {
 	return a, nil
 }
 
-var _solcSolcJsonTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x91\xbf\x6a\xf3\x40\x10\xc4\x7b\x3f\xc5\xb2\xb8\xfa\x10\xc2\x7c\xa5\x20\x4d\x02\xe9\xd2\xc4\x65\x48\x71\xd2\x8d\x95\x4b\x4e\x7b\x42\x5e\x85\x38\xc7\xbd\x7b\x10\x3a\xff\x8b\x41\xe9\x74\x33\x3f\xcd\x0c\x6c\x5c\x11\xb1\x37\xd2\x8e\xa6\x05\x57\xc4\xdb\xe0\x9d\x75\x7a\xe0\x62\x72\xf6\x61\x1c\x1a\xec\xb9\xa2\x09\x24\x8a\x71\x30\xd2\x82\xd6\x4e\x2c\xbe\x0a\x5a\x77\x46\x9b\x37\xaa\xee\xa8\x4c\x29\x13\x6e\x97\xed\x94\x8a\x18\x21\x36\x3b\x1c\xe3\x8c\x97\x8f\xce\x43\x4c\x87\x94\x4e\xc1\x44\xdc\x04\x51\x88\x4e\xd2\x11\x7c\x98\xa5\x1c\x70\x2c\x38\x46\xa6\x79\x22\x54\x9d\xb4\xe7\x8d\x1c\x7a\x75\x9d\xfb\xc6\x70\x91\x4e\xc4\x10\x53\x7b\x58\xae\x48\x87\x11\xc5\xd9\x18\x46\x99\xfe\xfe\xbf\xd9\xcc\x35\x45\x8e\x19\xb5\x1f\x75\x0b\x8f\x46\x5d\x90\xcb\xa9\xff\xae\x93\xa7\xe7\xcb\xe9\x49\xc4\xa6\x76\x5c\x5c\x09\x7b\xbd\x16\xf0\xd9\x95\xf5\x41\xd1\x04\x8b\x32\xd4\xef\x68\x96\x80\xf9\x0c\x4f\xa6\x5f\x60\xbc\x93\x8f\x67\xec\x30\x40\xa6\x8b\xdd\x80\x16\xbd\x0f\x07\xd8\xfb\xbf\x5a\x6f\xc0\x85\xf6\x1b\xf6\xd7\x8a\x13\xff\x9a\xbf\xce\x97\x4c\xab\xb4\xfa\x09\x00\x00\xff\xff\x6a\xb1\x78\x5f\x7e\x02\x00\x00"
+var _solcSolcJsonTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x35\x8e\x41\x0a\xc3\x30\x0c\x04\xef\x7d\x85\x30\x39\x1a\x3f\x20\xd0\x53\xa1\xff\x30\x8e\xea\x1a\x12\x05\x12\x07\x0a\x42\x7f\xaf\x1c\x5b\xc7\xd5\x8e\x76\x97\x1f\x00\x6e\x8d\x94\xaf\x98\xd1\xcd\xe0\x98\x83\x49\x11\xe7\x9b\x7d\xee\xd7\x91\xf0\x54\x97\x55\x02\x30\x1f\x4a\x20\x4c\x85\x16\xfc\x79\x98\xb6\x58\xd3\x17\xe6\x27\x84\x81\x8a\x0c\xb0\x7c\x06\x25\xe2\x99\x91\x96\xe1\x68\x4d\xff\x0a\xef\xb2\x22\xc5\xad\x95\x59\xbe\xda\x69\xa7\x8a\x54\xdb\xc9\xc0\x57\x3f\x8d\x00\x2b\xb0\x48\xe9\x4b\xb1\xd6\x42\xf9\x9e\xca\xc1\x94\x02\xf2\x07\xb5\x0e\xcc\x83\xe8\x00\x00\x00"
 
 func solcSolcJsonTplBytes() ([]byte, error) {
 	return bindataRead(
@@ -678,12 +638,12 @@ func solcSolcJsonTpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "solc/solc.json.tpl", size: 638, mode: os.FileMode(436), modTime: time.Unix(1517899410, 0)}
+	info := bindataFileInfo{name: "solc/solc.json.tpl", size: 232, mode: os.FileMode(436), modTime: time.Unix(1792322649, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _testTestGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8e\xb1\x4e\xc3\x30\x10\x86\xe7\xdc\x53\x1c\x19\x50\x52\x21\x47\xac\x48\x99\x20\xc0\x04\x52\x93\xce\xc8\x75\xdd\xd4\xa4\xb5\xad\xf3\xa5\x51\x15\xf9\xdd\x51\x4a\x2b\x94\x01\x75\xb2\x4f\xff\x77\xf7\x7f\x5e\xaa\x4e\xb6\x1a\x59\x07\x0e\x00\xe6\xe0\x1d\x31\x66\x90\x08\x4c\x5b\xe7\xbb\x56\x18\x5b\xa8\x9d\x56\x9d\x38\x3e\xa6\x00\x49\xda\x1a\xde\xf5\x6b\xa1\xdc\xa1\xf0\x6e\x7f\x0a\x83\xa4\x43\xe1\x35\x99\xd6\xd1\xa6\x50\xce\x32\x49\xc5\xe9\x4d\xd2\x6a\x1e\x1c\x75\xb7\xc1\x49\xcd\xd8\xf6\x5c\x3e\x8e\xc2\x93\xfb\xd6\x8a\x63\x2c\xd6\xc6\x6e\x8c\x6d\x43\x0a\x39\x00\x9f\xbc\xc6\x71\x14\x13\x1d\x63\xdd\x1b\xd6\x18\x98\x7a\xc5\x38\x02\x22\xe2\xa5\x6f\xfa\xe2\xe2\x32\x88\x8f\xdf\x17\x22\xc0\x51\x12\x7e\x61\x89\xe7\xd5\xec\x7e\x7e\x6a\x8c\x39\xc0\xb6\xb7\x0a\xb3\x80\x8b\x79\x96\x63\xad\x79\xe5\x1b\x1d\x38\x53\xb8\x78\xce\x71\x84\xc4\x0e\x0f\xa8\x89\xf0\xa9\xc4\x8b\xbf\xf8\xa3\x72\x48\xcc\xf6\x1c\xdf\x95\x68\xcd\x7e\x5a\x48\x94\x78\x95\x2c\xf7\x99\x26\xca\x21\x89\x00\x49\x10\x57\xe7\x12\xed\x30\x39\xfe\x6b\xd0\x68\x49\x2f\x6e\xb0\x73\x89\x6b\xf3\x2c\xcd\xa7\x43\x45\x81\xab\xba\x5a\x62\x53\xd5\x4d\x8d\x6f\x9f\xf8\x5e\x2d\x2b\xf8\x09\x00\x00\xff\xff\xe4\xd6\x79\x6c\x0d\x02\x00\x00"
+var _testTestGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x8e\x31\x6f\x83\x30\x10\x85\x67\xee\x57\x5c\x19\x2a\x40\x95\x51\xd7\x4a\x4c\x29\x6d\xa7\x56\x0a\x64\xae\x1c\xc7\x21\x16\xc4\x46\xf6\x11\x14\x21\xfe\x7b\x0d\x25\xaa\x18\xaa\x78\xf1\xdd\xbd\x77\xf7\xbe\x96\x8b\x9a\x57\x12\x49\x3a\x72\x00\xea\xdc\x1a\x4b\x18\x41\xc0\x30\xac\x4c\x5b\x57\x4c\xe9\x54\x9c\xa4\xa8\xd9\xe5\x39\x04\x08\xc2\x4a\xd1\xa9\xdb\x33\x61\xce\x69\x6b\x9a\xab\xeb\xb9\xf5\x95\xb4\xaa\x32\xf6\x90\x0a\xa3\xc9\x72\x41\xe1\x5d\xa7\x96\xd4\x1b\x5b\xdf\x37\x4e\x68\x4a\x57\x73\xf8\x30\xb0\xbd\xd2\x07\xdf\xba\x71\x0c\x21\x06\xa0\x6b\x2b\xd1\x8f\x27\xd7\x38\x16\x9d\x22\x89\x8e\x6c\x27\x08\x07\x40\xff\x96\x9c\xa9\xc4\x64\x69\xd8\xe7\xef\x0f\x23\xc0\x85\x5b\xfc\xc6\x0c\xe7\xd5\xe8\x71\x7d\x6a\x18\x7d\xc4\xb1\xd3\x02\x23\x87\xc9\x5a\x8b\xb1\x90\xb4\x6b\x4b\x3f\x89\x04\x26\x9b\xd8\x07\x06\xba\x7f\x42\x69\x2d\xbe\x64\xb8\x70\xb3\x3f\x57\x0c\x81\x3a\xce\xf2\x43\x86\x5a\x35\xd3\x42\x20\xd8\x1b\x27\xde\x44\x7e\xec\x75\x0f\x14\x38\x76\x63\xf6\xae\x7e\x62\xfc\x97\xa0\x94\xdc\xbe\x9a\x5e\xaf\x21\x6e\xc9\x2b\x35\x9e\x0e\xa5\x29\xee\x8a\x7c\x8b\x65\x5e\x94\x05\xbe\x7f\xe1\x47\xbe\xcd\xe1\x07\xf1\xe6\xbc\x37\x05\x02\x00\x00"
 
 func testTestGoTplBytes() ([]byte, error) {
 	return bindataRead(
@@ -698,7 +658,187 @@ func testTestGoTpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "test/test.go.tpl", size: 525, mode: os.FileMode(436), modTime: time.Unix(1517899410, 0)}
+	info := bindataFileInfo{name: "test/test.go.tpl", size: 517, mode: os.FileMode(436), modTime: time.Unix(1792321764, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _projectWbYamlTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x55\x5d\x6f\xdb\x36\x14\x7d\xd7\xaf\x20\x92\x57\xc7\x56\xdc\x65\x59\x05\xf4\xa1\x5b\xd1\xb7\xb5\xc3\x52\xac\x0f\x43\xa1\xd0\xd4\xb5\xc5\x99\x22\x09\x92\xb2\xe3\x14\xfd\xef\x3b\x94\x48\x59\x4e\x5e\xca\x00\xb1\x74\xee\x07\x2f\xcf\x3d\x97\xb2\xce\xfc\x47\x22\x54\xec\xfb\xf7\xa5\x1d\x9f\x7f\xfc\x28\x94\x14\xa4\x3d\x0d\x68\x7a\x5e\x7e\xe2\x1d\xc1\x54\x5c\xb3\x7f\x4e\x96\x1c\x13\x46\x07\xc7\x45\xf0\xcc\x68\x75\x62\xbd\x27\x66\x6c\x90\x9d\x7c\x26\xb7\x24\xcd\x37\x8a\x1a\xc6\x75\xc3\xe8\xd0\xd5\x07\x72\x5e\x1a\x5d\x08\xd3\x59\xa9\xc8\x55\x05\xc3\x9a\xfc\xc7\xd7\xb8\x52\x60\xc5\x82\xeb\x69\x42\x5d\xaf\x7d\xc5\xd6\x65\x39\x20\xd7\xf3\x94\x15\xdb\x9c\x9e\xb9\x0e\xb2\xef\x92\xd1\x51\xc7\xad\x95\x7a\xe7\xab\x84\xc4\x75\x83\xdd\x48\x3f\x93\xb5\xa4\xa4\x5e\xbd\x53\x72\xb3\xba\x40\xa6\xf3\xac\x52\x94\xd4\x42\xf5\x0d\xd5\x96\x87\xf6\x45\x2a\x6d\x80\x77\xa6\xe9\x15\xf9\x64\x38\x72\xa7\xe3\x9e\x35\xf7\x35\x39\x67\x1c\x0a\xde\x72\xe5\x29\xd9\x7d\x6f\xad\x23\xef\xa9\xa9\x05\xa2\x5f\x24\xbc\x5a\x97\xf7\xeb\xab\x04\x19\x1c\xcd\xc9\x57\x3e\x53\x81\x15\xfb\xf7\xa3\x31\xdf\x66\xc6\x57\x54\x66\xf8\x4c\xde\x6d\x09\xf6\xd0\x3c\xee\x82\xdc\x0e\x59\x8a\xd1\x69\x6b\x5c\xc7\x21\x00\x6f\x95\x0c\xb1\xd0\xf8\xbb\xc0\x6e\xdd\x46\x6a\xb4\xd0\x38\xb6\x31\xa1\x8d\xb1\x1e\x1b\xe4\xb0\x78\xde\x1a\x3a\x80\x38\x10\xfc\xb6\x4c\x70\xac\xbd\x56\x28\x05\x60\x74\x49\xcc\xc4\x2c\x5b\x2e\x55\xcc\x02\x99\x3d\x49\x9a\xf6\x07\x9b\x50\x56\xc5\x3e\x48\xde\x19\xdd\x14\xb9\xf4\xf9\x71\x93\x6d\xc1\x3e\x1f\x35\xda\xde\x4a\xfb\x91\x0b\x0a\xdf\x62\x3a\x54\xd9\x8c\xcd\x1e\x23\x2d\x17\x7b\xbe\x43\xc2\x6c\xc8\x95\xf5\xc1\xf6\xe1\x15\x1c\x20\xe6\x29\x36\x2e\x50\x5b\xc5\x7f\x7f\xa4\xfd\xe3\x1e\xc7\xcd\xd0\x72\xe6\xc9\x1d\xc8\x33\xce\x94\x11\x5c\x31\xd1\x72\xa9\x23\x81\x2c\xb4\xc4\x1a\x3a\x30\x4d\xe1\x68\xdc\x1e\x21\xd1\x3f\xa7\x4d\x68\x15\x5d\x12\xd4\x86\x60\xd1\x94\xf5\xfd\xb2\xc4\xdf\x6d\xf5\xdb\xdd\x2f\x77\xb1\xf5\x0e\x81\x9a\x92\x93\xb4\x02\xf5\xf6\x52\x35\xab\x98\x6e\x89\xf7\x57\x3e\x43\x0d\xb5\xc4\xc4\xdc\xbe\x79\x73\x9f\xc0\x0d\xea\xdb\xd7\x50\x04\x68\xb8\xf3\x88\x29\x59\x87\x66\x7a\x46\x5c\xb4\x18\x2d\xae\x3d\x4e\x86\xe9\x61\x3c\x60\x7c\x45\x4e\xc6\x85\x30\xbd\x0e\x83\x5c\x72\x2a\xae\x38\x1c\x46\x01\x95\x71\xf6\x70\x56\xc7\xe2\x0d\x90\xbc\x93\x63\x27\x77\x8e\x07\xca\xaa\x1f\x41\x28\x9e\x42\x82\x10\x2b\x14\xf1\x4c\x96\x55\xe6\xd4\x41\x3c\x1e\x4e\xc2\xb8\x06\x52\xcb\x4c\x66\x16\x91\xe4\xd3\xf8\xc8\x1e\xd1\x82\x40\x3e\x3c\x0e\x72\x1e\x1e\xe3\xc5\xb3\x60\xbd\xc6\x0c\x7a\xb6\x93\x07\xd2\xec\xe6\x26\x87\x46\x87\x7a\xe2\xdd\xcb\xae\x57\x28\xae\x29\x8a\x84\x4d\xb3\xf5\x5e\x63\xd0\x6f\xa0\x49\x11\xd3\x8c\x1d\x0d\x2d\x68\x19\xa9\x42\x1a\x8c\xae\x0c\xe8\x7d\xc0\xe8\xa0\xf7\x5b\x9c\xa9\x1d\x82\xa7\xac\xe7\xeb\x2b\xaa\x69\xbe\x5d\xc6\xaf\x2f\xa9\x3d\xc3\x3f\x41\xef\xe8\xf8\x05\xbc\x8c\xd5\x3d\x26\x35\x3e\x26\x39\x2e\x12\x99\x10\x35\xdb\x3a\xd3\x0d\x14\xe6\xed\x18\x66\xda\xf3\x43\xba\xa6\xa0\xbf\x73\xad\xbd\x53\xd5\xa8\xc3\xd5\xea\x52\x88\x93\xcb\x9e\x4e\x3e\x18\x47\x73\x15\xae\x32\x38\x3b\xc5\xa5\x08\x07\x80\xc4\x1e\x1d\xdd\x10\x7a\x8a\x6a\xf4\x29\xb4\xb1\x3e\xe9\x53\xb1\x17\xdc\xec\x70\x67\x5a\x27\x23\x0d\xeb\xc8\xc1\xee\x48\xf2\x85\x39\x5d\x29\xbf\x96\xc3\x9a\x19\x53\x3a\x57\xb1\x18\xc9\x9b\x26\x5e\xb2\x71\x46\x72\x99\x68\x6f\x43\x4f\x0b\x90\x45\x71\x8e\x27\x62\x94\xf4\x61\x96\xc7\x72\xef\x21\x8c\xa6\xde\xe2\xfb\x54\xb1\x65\x7e\x1f\x07\x0e\x2a\x66\x5f\x7f\xaf\xff\x7a\xff\xf0\xf0\xf5\xf3\xdf\x1f\x66\x71\xf8\x8e\x38\xee\x64\xbe\xac\xcf\x97\xef\x03\xdf\xd2\x9f\xf8\x74\x54\xec\xaa\x7c\x2a\x7f\x72\x5d\x15\xff\x03\x5d\x68\xf6\x99\x91\x07\x00\x00"
+
+func projectWbYamlTplBytes() ([]byte, error) {
+	return bindataRead(
+		_projectWbYamlTpl,
+		"project/wb.yaml.tpl",
+	)
+}
+
+func projectWbYamlTpl() (*asset, error) {
+	bytes, err := projectWbYamlTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "project/wb.yaml.tpl", size: 1937, mode: os.FileMode(436), modTime: time.Unix(1792324930, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _projectGoModTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcb\xcd\x4f\x29\xcd\x49\x55\xa8\xae\xd6\x2b\x28\xca\xcf\x4a\x4d\x2e\xa9\xad\xe5\xe2\x4a\xcf\x07\x09\xa4\xe7\x03\xd9\xd5\xd5\xba\x0a\x99\x69\x0a\x7a\x45\xa9\x85\xa5\x99\x45\xa9\xc5\x20\x69\x28\x5b\x41\x03\x2c\x5b\x94\x98\x97\x9e\x8a\xa2\x80\x13\xa8\x39\x20\xb1\x24\xa3\xb6\x16\x64\x4c\x58\x6a\x51\x71\x66\x7e\x1e\xd4\xac\xd4\xbc\x14\x20\x4b\x13\x89\x0d\x00\xf0\x0b\x4e\x1d\x82\x00\x00\x00"
+
+func projectGoModTplBytes() ([]byte, error) {
+	return bindataRead(
+		_projectGoModTpl,
+		"project/go.mod.tpl",
+	)
+}
+
+func projectGoModTpl() (*asset, error) {
+	bytes, err := projectGoModTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "project/go.mod.tpl", size: 130, mode: os.FileMode(436), modTime: time.Unix(1792325258, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _projectMigrations1_migrationsGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcd\x55\xc9\x6e\xdb\x30\x10\x3d\x8b\x5f\xc1\xea\x10\x48\x86\x4a\x21\x40\xd1\x83\x8a\x1c\x9a\x14\x45\x2e\x49\x8b\x2e\xe8\x99\xa6\x18\x9b\x88\x44\x0a\x14\x85\xc4\x35\xfc\xef\x1d\x52\x24\xe5\x25\x81\xe3\x06\x05\xea\x83\x45\x91\xb3\xbc\x79\x6f\x38\xea\x28\xbb\xa7\x0b\x8e\x5b\xb1\xd0\xd4\x08\x25\x7b\x84\x44\xdb\x29\x6d\x70\x86\x92\x94\x29\x69\xf8\xa3\x49\x11\xac\x17\xc2\x2c\x87\x39\x61\xaa\x2d\xb9\x59\x72\xcd\x87\xb6\x5c\xa8\xb7\x71\x4d\x19\x53\x83\x34\x7d\x49\xe7\xa2\x9c\x0b\x59\xa7\x2f\x70\x82\x83\x56\xc9\x97\x59\x6a\x5e\x9a\x55\xc7\xfb\x7d\x34\x9d\x6a\x56\xfd\x03\xd5\xb0\xe2\x5a\x2c\x94\xae\x4b\x8b\x5b\x53\x66\xd2\xa3\x96\xb1\xf0\x13\x4c\x5d\x75\x42\x2e\xfa\xe3\x3e\x92\x9b\x07\xa5\xef\xf7\x0c\x7f\xf7\x4c\x35\xbc\x64\x8d\x28\x6b\xde\x35\x6a\xc5\xf5\x31\x8b\x96\x03\xb5\x29\xca\x11\xb2\x1c\xe0\x9b\xa8\xd7\x27\x1f\x00\xf7\x46\x0f\xcc\xac\x37\x08\xdd\x0d\x92\xe1\xac\xc6\xb3\x43\xab\x1c\x8f\xab\x8c\x99\x47\xec\xd5\x25\x57\xe3\xb3\xc0\x1e\x2d\x9e\xf9\x05\xb9\x1d\x9f\x39\xce\x46\xa1\xc8\xc7\xba\xd6\xbc\xef\x0b\x3c\x73\x52\x90\x1f\x9a\xca\x1e\x88\x86\x1c\x05\x16\x10\x46\xdf\x51\xc6\xd7\x9b\x02\x73\xad\x15\xa4\x5b\xa3\xc4\x37\x86\xdb\xc2\xd5\x05\x0e\x25\x93\x9f\xb2\x51\xec\x3e\xf3\xc9\x72\x94\x88\x3b\x67\xf3\xe6\x02\x4b\xd1\x58\xd7\x44\x73\x33\x68\x89\x77\xb3\xdb\xf0\x60\xe0\xff\xc0\x03\x25\x50\x75\x42\x07\xb3\xb4\xf1\x27\xf0\x0f\x01\x9e\xd2\x99\x47\x01\x59\x68\xa8\xc1\x6c\x83\x0f\x2d\x13\x61\x06\x91\xc9\xc8\xd8\xc4\x65\x66\x13\x45\xb2\xc8\x55\x23\x40\x9b\x2c\x7f\x35\x7e\xcd\x19\x17\xdd\x2e\x00\xf2\x8b\x0a\x73\x23\x24\xaf\xad\x62\x87\x49\x77\x8a\x78\x35\x02\xeb\x1a\xf4\x71\x0d\x47\xbe\x71\xb8\x77\xf5\x95\xe7\x26\x4b\x27\x16\xd2\x62\xc7\x30\x98\xd8\x94\x3e\x49\x85\xdd\xcf\xd3\x4d\xae\xf9\x23\xe0\x85\xe3\xad\x9e\x01\x93\x2d\xfc\xe4\x9a\xf6\xcb\x2c\x9f\x2c\x2f\x6d\x7f\xf8\x30\x18\x7b\x7e\x88\xdb\xbd\x1d\xda\xb9\x6d\x21\xe8\xb9\xf7\xef\xbc\xf5\xca\x00\xda\x9a\xdb\x28\xd5\x0e\xb8\xed\x93\x2c\xea\x3a\xd5\x72\x29\xa4\x8b\x10\x77\x5c\xce\x73\xd8\xda\xbc\x9a\xd3\x1e\x8e\x20\xa2\x15\xf4\xec\x89\xd4\xdf\xc7\x63\x1b\x35\x50\x58\x4d\xbd\x68\x77\x69\xd3\x7c\xe9\x0c\xb0\xe9\xfa\x21\xbc\x5a\x87\xe4\x2b\x77\xf1\x2a\x20\x71\xe0\xd6\x78\xb3\xcd\xef\xe8\x35\x73\xdd\x1a\x3a\xcc\xe1\x7e\xfa\x02\x78\xa0\x0e\x3f\x3a\x36\x45\x80\xb1\xfa\xb4\x19\x52\x84\xb4\x7b\xb4\xc1\x6c\xf9\x77\x73\xe3\xef\x06\xc4\xf3\xa3\x00\x3c\xb6\xe7\x40\xe0\xf1\x94\x51\xf0\xdf\x77\xc7\x93\x7d\x20\xa4\x80\xba\x6c\x1d\x21\xbb\xd5\xef\x99\xb9\x70\x76\xd8\x31\x6b\xb8\x48\x28\x89\x5f\x50\xeb\x1c\x8d\xb2\xb3\x69\x3f\x6e\xda\x0a\xc6\x3b\x5e\xb9\x8b\x98\x7c\xae\xb0\x45\x72\xea\x87\xcb\xb5\x93\xe3\x3f\x48\x02\x54\xc7\x1a\xa6\x8f\x61\x81\x77\x6b\x08\xcd\xf5\x61\x5f\xc6\x28\xa4\xd3\x30\x71\xcc\xc5\xbd\xc3\xd1\x39\x55\x79\x9e\x7b\x09\x80\x8a\x0d\xfa\x03\x2c\xd5\x60\x06\x79\x09\x00\x00"
+
+func projectMigrations1_migrationsGoTplBytes() ([]byte, error) {
+	return bindataRead(
+		_projectMigrations1_migrationsGoTpl,
+		"project/migrations/1_Migrations.go.tpl",
+	)
+}
+
+func projectMigrations1_migrationsGoTpl() (*asset, error) {
+	bytes, err := projectMigrations1_migrationsGoTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "project/migrations/1_Migrations.go.tpl", size: 2425, mode: os.FileMode(436), modTime: time.Unix(1792324069, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _contractContractVyTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x53\x56\x70\x28\x4b\x2d\x2a\xce\xcc\xcf\x53\x88\x33\xd0\x33\xd6\x33\xe7\xe2\x72\x48\xad\x28\x49\x2d\xca\x4b\xcc\xe1\x4a\x49\x4d\x53\x88\x8f\xcf\xcc\xcb\x2c\x89\x8f\xd7\xd0\xb4\xe2\x52\x00\x82\x82\xc4\xe2\x62\x2e\x00\x07\xfb\x00\x0b\x36\x00\x00\x00"
+
+func contractContractVyTplBytes() ([]byte, error) {
+	return bindataRead(
+		_contractContractVyTpl,
+		"contract/contract.vy.tpl",
+	)
+}
+
+func contractContractVyTpl() (*asset, error) {
+	bytes, err := contractContractVyTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "contract/contract.vy.tpl", size: 54, mode: os.FileMode(436), modTime: time.Unix(1792322665, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _docsContractMdTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb5\x93\x4b\x4e\xc3\x30\x10\x86\xf7\x39\x85\xd5\xee\x22\x35\x07\xe8\x0e\x51\xd8\x20\x10\x82\x1e\x20\xc1\x99\x52\x4b\x8e\x1d\x9c\x09\xa8\x72\x7d\x77\xfc\x4a\xec\x14\x16\x74\xc1\x26\x99\xc7\xef\x6f\xec\x19\x5b\xeb\x16\x0e\x4c\x00\x59\xf5\x8d\x6a\xba\x61\x65\xcc\x99\x6c\x36\x1b\xb2\xf8\x16\x5a\xab\x46\xbc\x03\xa9\x5c\x5a\x6b\x76\x20\xd5\x53\xd3\x81\x31\xb5\xd6\xc9\x02\xd1\x1a\x43\x26\xc1\xfe\xd4\x47\xc1\x6c\x25\x41\xb5\x93\xd4\x99\x45\x0c\xce\xbf\x69\x3b\x1d\x74\x6f\xa0\xec\x76\x8a\xf5\x7a\xbd\x28\x39\x57\xb4\x6b\xf8\x10\x02\x0f\x2c\x83\x14\x45\x5d\xd7\x83\xe4\xac\x65\x78\x2a\x5c\x2d\xa0\xdc\x9e\x0e\x99\x14\x36\x6b\x93\x45\xe0\xbd\x02\x07\x8a\x52\xd9\xa0\x0f\xc0\x07\xf1\x24\xb2\x82\x4f\x10\x68\x8b\xef\x65\xcf\xe8\x54\x67\x92\xc7\x32\x5b\xe2\xce\x96\x18\x75\x3a\x8b\xdf\xac\x44\x46\xc1\xa3\x73\x3b\x53\xec\x00\x1b\xc6\x87\x20\xc9\x9d\x4c\xf3\xec\xa7\x62\xa3\x67\xe2\x4d\x40\x50\xb6\x81\xae\xa3\xf6\xb7\x83\x81\x2a\xd6\xbb\x83\xf9\x56\x22\x74\x3d\x6f\x30\x4d\x33\x01\x16\xd4\x17\xc0\x51\x89\x80\x8d\xf6\x55\xd0\x79\xfd\xe5\xf4\xbe\x18\x1e\x49\x45\xa5\x40\xd5\x50\x34\xc6\x4d\x2e\x4e\x2b\xf6\x7c\xcf\x90\x3b\xaf\x2c\xdd\xc5\x08\x4e\x59\xfe\x47\xeb\x6e\x46\x3c\xfa\xd9\x06\x63\xeb\xb6\x32\xc7\x16\xca\x5b\x29\x06\x54\x63\xbc\x0a\xf6\xba\x65\x81\x45\x03\xe2\xa5\xbc\x58\xb1\x60\xdd\x8f\x82\xba\xd6\x0d\x81\x34\xbb\xe9\x09\x65\x8a\x5f\xd9\x3f\xbb\xea\xb8\x77\xee\x46\x46\x68\xb0\x13\x71\xca\x5d\x83\x53\x4a\xaa\x09\xe7\xed\x0c\x17\x73\x57\xe0\x1e\x65\xcb\x0e\x0c\x26\xe2\xec\x26\x68\xa6\xf8\x23\x37\xbc\xe5\x6f\x73\xeb\x20\x33\xa1\x04\x00\x00"
+
+func docsContractMdTplBytes() ([]byte, error) {
+	return bindataRead(
+		_docsContractMdTpl,
+		"docs/contract.md.tpl",
+	)
+}
+
+func docsContractMdTpl() (*asset, error) {
+	bytes, err := docsContractMdTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "docs/contract.md.tpl", size: 1185, mode: os.FileMode(436), modTime: time.Unix(1792323143, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _docsContractHtmlTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbd\x54\xc1\x6e\x9c\x30\x10\xbd\xef\x57\xb8\xdc\xb3\xa8\xc9\xa5\x8a\x58\xa4\x28\xa4\x97\xaa\x6d\xd4\xee\xa5\x47\xaf\x19\x62\x4b\x80\xa9\x31\x6d\x23\xb4\xff\xde\x31\x1e\x1b\x58\x52\xa9\x51\xa5\x9e\xb0\x9f\x67\xde\x9b\xf1\x3c\x33\x8e\x25\x54\xaa\x05\x96\x74\xdc\xf0\xa6\x4f\xce\xe7\x71\x34\xbc\x7d\x02\xb6\x3f\x9f\x33\x6b\xf2\xcc\x96\xf9\x38\xaa\x8a\xed\x3f\xf1\x06\x10\x13\xba\x04\x44\xa4\x6d\xea\x88\xa5\x04\x42\x5b\xba\x1d\xa6\xcc\x69\xc7\xe7\x6e\x93\x46\xd8\x9f\xd2\x7c\x50\xa1\x45\x40\x53\x2c\x64\x37\xc7\xf1\x53\x0d\x71\x3f\xc6\x16\x1a\x68\x4e\x60\xb0\x85\xac\x07\x61\x95\x6e\x99\xa8\x79\xdf\x1f\xc2\x41\xbe\xcb\xe4\x0d\x53\xe5\x21\x09\x12\x1f\x94\x63\xb8\x5a\x77\x93\xac\xfa\x5d\x9f\xa1\x66\xdd\x2f\x50\x4f\x10\x2b\x93\x37\xa8\xd1\x19\xc8\xd7\xed\x16\x80\x85\x18\xee\x4a\x8a\x5d\x67\xa9\x8b\xdb\x79\xa9\xaf\x50\x63\xc5\xda\xe0\x69\xe7\xd5\xe1\xbb\x27\x67\x09\xfc\x80\xd6\x62\x53\x47\xdd\x29\x11\xf4\x43\x02\x29\xdf\xb2\x20\xb8\xa4\x8a\x42\x8b\xbb\x9a\x1a\xd3\x56\x09\x20\x2d\xea\x2e\x40\x9b\xe0\x02\x2c\x57\x75\xbf\x8a\x9e\xb1\x4d\xf8\xe3\x64\x23\x67\x1d\x3f\x24\x6f\x21\x99\x4f\x38\x58\x30\x38\x3e\x39\x21\xce\x03\x71\x53\x40\x2f\x8c\xea\xdc\x0d\x79\x8c\x26\x6e\xa1\xe9\x6a\x6e\x67\x7f\xce\x0a\x2b\xd9\x2f\x60\x07\xd3\xbe\xa0\x4b\x07\xff\xac\x1a\x05\xe2\xac\xc9\x63\x8b\xfe\x7f\x2a\x2b\xd9\x5e\xe8\xd6\x1a\x2e\x2c\xc6\xbc\x29\x3e\xdf\x1f\xbf\x3d\x3e\x30\x77\x6d\xce\x7d\xf4\x01\x5e\xe2\x07\xaf\x83\x33\x21\xb9\xe9\xc1\x1e\x92\xc1\x56\x57\xef\x9c\x45\xad\xb2\xf5\xf6\x81\x79\x74\x87\x16\xf3\xc9\x27\x5d\x3e\x3b\xaf\xe5\x19\x67\xd2\x40\x75\x48\xd0\x2c\xf0\x6b\xef\xb2\x92\xfc\x9e\x6a\xc0\xbe\xb9\x37\x40\x26\xdf\x6e\x38\x11\x22\xff\x1d\x1d\xfb\x34\xe2\xac\xb7\x46\xb7\x4f\xf3\x4b\xa5\x93\x94\xf0\xff\xeb\xa6\xbb\xc1\x4a\x7a\x15\x7e\x79\xcb\x42\x56\x3c\xda\x24\x61\xf3\x58\xec\x40\x8f\x40\x5e\xe7\x0b\x00\x9b\xbe\x5e\x4f\x98\xfe\x0d\x17\x69\x2b\xc2\xf7\x43\x3b\x8d\xba\xf7\x74\x71\x1b\xc8\xe8\x7f\xb9\x08\x7b\x51\x60\xc1\xba\x20\x7f\x70\xcf\x9b\x98\xfd\xfa\x82\x36\x04\xbc\x86\xd3\x18\x6d\x02\xe7\xb4\xbe\xe4\xa4\x80\x57\x70\x7e\xd4\xa5\xaa\x14\x04\xda\xb8\xbd\x60\x5e\x84\xfd\x15\x79\x96\x92\x93\x53\xff\x38\x08\xde\xfd\x06\x1c\x0b\x01\x33\x99\x06\x00\x00"
+
+func docsContractHtmlTplBytes() ([]byte, error) {
+	return bindataRead(
+		_docsContractHtmlTpl,
+		"docs/contract.html.tpl",
+	)
+}
+
+func docsContractHtmlTpl() (*asset, error) {
+	bytes, err := docsContractHtmlTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "docs/contract.html.tpl", size: 1689, mode: os.FileMode(436), modTime: time.Unix(1792323143, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _docsIndexMdTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x53\x56\x70\xce\xcf\x2b\x29\x4a\x4c\x2e\x29\xe6\xe2\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\x4b\x86\x89\xd5\xd6\xea\x2a\x44\x57\x57\xeb\xf9\x25\xe6\xa6\xd6\xd6\xc6\x6a\xc0\x99\xd5\xd5\x2a\x7a\xa9\x15\x25\xb5\xb5\x9a\xd5\xd5\x99\x69\x0a\x7a\x21\x99\x25\x39\x40\x61\x2b\x05\xa0\x0a\x28\xbb\xba\x3a\x35\xa7\x38\x55\x01\x24\xeb\x97\x5f\x92\x99\x0c\x93\x86\x71\x80\xf2\x79\x29\xb5\xb5\x5c\x30\x1a\x00\xf8\xfd\x41\x78\x8b\x00\x00\x00"
+
+func docsIndexMdTplBytes() ([]byte, error) {
+	return bindataRead(
+		_docsIndexMdTpl,
+		"docs/index.md.tpl",
+	)
+}
+
+func docsIndexMdTpl() (*asset, error) {
+	bytes, err := docsIndexMdTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "docs/index.md.tpl", size: 139, mode: os.FileMode(436), modTime: time.Unix(1792323144, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _docsIndexHtmlTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x55\x90\x31\x0f\x82\x30\x10\x85\xf7\xfe\x8a\x4a\x5c\xa5\x71\x33\xe6\x60\x41\x57\x74\x60\x71\xac\xe5\x90\x26\x05\x12\x38\x12\x4d\xd3\xff\x6e\x1b\xa8\x91\xe9\x72\xef\xbe\x7b\x7d\x3d\xd8\x5d\x6e\x45\xf5\xb8\x5f\x79\x4b\x9d\xc9\x19\xc4\x82\xb2\xf6\xa5\x43\x92\x5c\xb5\x72\x9c\x90\xb2\x64\xa6\xe6\x70\x4a\xbc\x4c\x9a\x0c\xe6\xc5\xd0\xd3\x28\x15\x4d\x20\x16\x81\x81\x58\xf7\x9e\x43\xfd\x09\x2e\xc7\x7f\xc8\x77\x0c\x66\xef\x6e\xed\x28\xfb\x17\xf2\x54\xc5\xa1\x73\x60\x74\x0e\x92\xb7\x23\x36\x59\x62\x6d\x88\xc1\xd3\x52\x76\xe8\x9c\xb5\xfb\x14\xdf\xe4\x5c\x92\x6f\x07\x20\xa4\x57\x74\xc3\xd3\x2a\xbc\xef\xdc\x99\x47\x60\x15\xac\x45\x33\x21\x0f\x48\x39\x90\x56\x1b\x26\x2a\x1e\xea\xeb\xe0\xe6\x23\xb0\x5f\x13\x82\x82\x58\x3f\x22\x96\xb3\x7c\x01\xc8\xd5\x03\xd6\x2e\x01\x00\x00"
+
+func docsIndexHtmlTplBytes() ([]byte, error) {
+	return bindataRead(
+		_docsIndexHtmlTpl,
+		"docs/index.html.tpl",
+	)
+}
+
+func docsIndexHtmlTpl() (*asset, error) {
+	bytes, err := docsIndexHtmlTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "docs/index.html.tpl", size: 302, mode: os.FileMode(436), modTime: time.Unix(1792323144, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _testSimulatedGoTpl = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x52\xc1\x6e\xa3\x30\x10\x3d\xe3\xaf\x98\xcd\xa1\x82\x28\x32\xed\xb5\xab\x9c\x52\xb2\xbd\x55\x0a\xf4\x5c\xb9\xce\x04\xac\x10\x1b\xd9\x26\x55\x36\xe2\xdf\x77\xc0\x94\x24\x95\x56\x2d\x12\xc2\xcc\x78\xde\x7b\xf3\x66\x1a\x21\xf7\xa2\x44\xf0\xe8\xbc\x63\x4c\x1d\x1a\x63\x3d\xc4\x2c\xe2\x30\x2b\x4d\xb3\x2f\xb9\xd2\xa9\xac\x50\xee\xf9\xf1\x61\xc6\x58\x34\x2b\x95\xaf\xda\x77\x2e\xcd\x21\xfd\xeb\xa4\xa9\x31\x95\xb5\x4a\x9d\x3a\xb4\xb5\xf0\xb8\x9d\xb1\x84\x31\x7f\x6a\x10\xce\x67\xde\xa3\x76\x5d\xde\x2a\x8f\xe0\xbc\x6d\xa5\x87\x33\x8b\x64\x25\x94\x06\x7a\xe6\x53\x15\x5f\xf5\x31\x16\x39\x2d\x1a\x57\x19\x0f\x4a\x7b\xd6\x31\x76\x14\x16\xde\x60\x09\x03\x44\x7c\x77\x0b\x79\xee\x88\x6a\xd7\x6a\x09\xb1\x83\xf9\x6d\x2e\x81\x1c\xfd\x6b\x13\xea\x24\xcc\x57\xc9\xc4\xbc\x00\xb4\x16\x1e\x97\x70\x61\x5f\x5b\x73\xc8\xf4\x31\x4e\x58\xa4\x76\x43\xfa\xd7\x12\xb4\xaa\xfb\x9a\x48\xf2\xb5\xf0\xa2\x8e\x29\x4c\x79\x12\x15\x39\x1e\x5a\x58\xc2\xf0\xa5\x48\x9a\xc2\x13\x36\xb5\x39\x81\xaf\x10\xa4\xd1\xde\x0a\xe9\xdd\xf0\xe7\x42\xfb\x95\xb0\xe8\xa0\x42\x8b\x0b\xf8\x20\x0f\x87\xdc\xbb\xd2\x5b\xa5\x4b\x07\x7d\xf3\x04\x42\x4d\x7c\x86\xba\x8e\x84\xf2\x92\xf7\xf1\xfe\x8d\x44\xeb\xab\x05\xb9\xd1\x2b\x0f\x02\x78\x61\x85\x76\x44\x64\x6c\x7c\x9f\x84\x4b\xdb\x2d\xd1\x38\xba\xb7\x98\x64\x4c\x0d\x5f\xa1\xbf\x35\x61\xf0\x5d\xc7\x83\xf0\xb5\x31\x71\x60\x18\xc1\x93\xde\xff\xff\xba\x5b\xa0\xb0\x4f\xe6\x43\x7f\x31\xf8\x53\xd8\xaa\x36\x0e\xe3\x01\x82\xba\xca\x8e\x68\x4f\xc3\x8a\xd1\x16\x08\x4b\xbe\xec\xc8\xf0\x60\xd5\x60\xa4\x70\x57\xe3\x82\x1a\x77\xb4\x00\xfe\x9b\xd1\x16\x14\xb9\x22\x1e\x17\xe7\x32\xdc\x51\x49\x3e\x26\x7e\x3e\xdb\x69\x07\x09\x64\x3c\xfe\xc8\x8a\x5b\x41\x23\xd7\x95\x92\x0d\x92\x0d\x3e\xbe\x10\x24\xbf\xbf\x93\x13\xec\x7b\xcd\xb3\x0d\x14\x59\x5e\xe4\xf0\xe7\x05\x9e\xb3\x4d\xc6\xfe\x01\xba\x1c\x44\x09\xb7\x03\x00\x00"
+
+func testSimulatedGoTplBytes() ([]byte, error) {
+	return bindataRead(
+		_testSimulatedGoTpl,
+		"test/simulated.go.tpl",
+	)
+}
+
+func testSimulatedGoTpl() (*asset, error) {
+	bytes, err := testSimulatedGoTplBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "test/simulated.go.tpl", size: 951, mode: os.FileMode(436), modTime: time.Unix(1792324876, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -746,38 +886,45 @@ func AssetNames() []string {
 }
 
 var _bindata = map[string]func() (*asset, error){
-	"bindata.go":                         bindataGo,
-	"contract/contract.sol.tpl":          contractContractSolTpl,
-	"helpers.go":                         helpersGo,
-	"licenses/agpl/header.tpl":           licensesAgplHeaderTpl,
-	"licenses/agpl/text.tpl":             licensesAgplTextTpl,
-	"licenses/apache/header.tpl":         licensesApacheHeaderTpl,
-	"licenses/apache/text.tpl":           licensesApacheTextTpl,
-	"licenses/bsd/header.tpl":            licensesBsdHeaderTpl,
-	"licenses/bsd/text.tpl":              licensesBsdTextTpl,
-	"licenses/freebsd/header.tpl":        licensesFreebsdHeaderTpl,
-	"licenses/freebsd/text.tpl":          licensesFreebsdTextTpl,
-	"licenses/gpl2/header.tpl":           licensesGpl2HeaderTpl,
-	"licenses/gpl2/text.tpl":             licensesGpl2TextTpl,
-	"licenses/gpl3/header.tpl":           licensesGpl3HeaderTpl,
-	"licenses/gpl3/text.tpl":             licensesGpl3TextTpl,
-	"licenses/lgpl/header.tpl":           licensesLgplHeaderTpl,
-	"licenses/lgpl/text.tpl":             licensesLgplTextTpl,
-	"licenses/mit/header.tpl":            licensesMitHeaderTpl,
-	"licenses/mit/text.tpl":              licensesMitTextTpl,
-	"migration/migration.go.tpl":         migrationMigrationGoTpl,
-	"project/.gitignore.tpl":             projectGitignoreTpl,
-	"project/contracts/Foo.sol.tpl":      projectContractsFooSolTpl,
-	"project/generate.go.tpl":            projectGenerateGoTpl,
-	"project/main.go.tpl":                projectMainGoTpl,
-	"project/migrations/1_Migrations.go": projectMigrations1_migrationsGo,
-	"project/cli.yaml.tpl":               projectCliYamlTpl,
-	"project/stub/README.md.tpl":         projectStubReadmeMdTpl,
-	"project/stub/main.go.tpl":           projectStubMainGoTpl,
-	"project/stub_test.go.tpl":           projectStub_testGoTpl,
-	"project/tests/Foo.go.tpl":           projectTestsFooGoTpl,
-	"solc/solc.json.tpl":                 solcSolcJsonTpl,
-	"test/test.go.tpl":                   testTestGoTpl,
+	"bindata.go":                             bindataGo,
+	"contract/contract.sol.tpl":              contractContractSolTpl,
+	"contract/contract.vy.tpl":               contractContractVyTpl,
+	"docs/contract.html.tpl":                 docsContractHtmlTpl,
+	"docs/contract.md.tpl":                   docsContractMdTpl,
+	"docs/index.html.tpl":                    docsIndexHtmlTpl,
+	"docs/index.md.tpl":                      docsIndexMdTpl,
+	"helpers.go":                             helpersGo,
+	"licenses/agpl/header.tpl":               licensesAgplHeaderTpl,
+	"licenses/agpl/text.tpl":                 licensesAgplTextTpl,
+	"licenses/apache/header.tpl":             licensesApacheHeaderTpl,
+	"licenses/apache/text.tpl":               licensesApacheTextTpl,
+	"licenses/bsd/header.tpl":                licensesBsdHeaderTpl,
+	"licenses/bsd/text.tpl":                  licensesBsdTextTpl,
+	"licenses/freebsd/header.tpl":            licensesFreebsdHeaderTpl,
+	"licenses/freebsd/text.tpl":              licensesFreebsdTextTpl,
+	"licenses/gpl2/header.tpl":               licensesGpl2HeaderTpl,
+	"licenses/gpl2/text.tpl":                 licensesGpl2TextTpl,
+	"licenses/gpl3/header.tpl":               licensesGpl3HeaderTpl,
+	"licenses/gpl3/text.tpl":                 licensesGpl3TextTpl,
+	"licenses/lgpl/header.tpl":               licensesLgplHeaderTpl,
+	"licenses/lgpl/text.tpl":                 licensesLgplTextTpl,
+	"licenses/mit/header.tpl":                licensesMitHeaderTpl,
+	"licenses/mit/text.tpl":                  licensesMitTextTpl,
+	"migration/migration.go.tpl":             migrationMigrationGoTpl,
+	"project/.gitignore.tpl":                 projectGitignoreTpl,
+	"project/contracts/Foo.sol.tpl":          projectContractsFooSolTpl,
+	"project/generate.go.tpl":                projectGenerateGoTpl,
+	"project/go.mod.tpl":                     projectGoModTpl,
+	"project/main.go.tpl":                    projectMainGoTpl,
+	"project/migrations/1_Migrations.go.tpl": projectMigrations1_migrationsGoTpl,
+	"project/stub/README.md.tpl":             projectStubReadmeMdTpl,
+	"project/stub/main.go.tpl":               projectStubMainGoTpl,
+	"project/stub_test.go.tpl":               projectStub_testGoTpl,
+	"project/tests/Foo.go.tpl":               projectTestsFooGoTpl,
+	"project/wb.yaml.tpl":                    projectWbYamlTpl,
+	"solc/solc.json.tpl":                     solcSolcJsonTpl,
+	"test/simulated.go.tpl":                  testSimulatedGoTpl,
+	"test/test.go.tpl":                       testTestGoTpl,
 }
 
 func AssetDir(name string) ([]string, error) {
@@ -811,6 +958,13 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"bindata.go": &bintree{bindataGo, map[string]*bintree{}},
 	"contract": &bintree{nil, map[string]*bintree{
 		"contract.sol.tpl": &bintree{contractContractSolTpl, map[string]*bintree{}},
+		"contract.vy.tpl":  &bintree{contractContractVyTpl, map[string]*bintree{}},
+	}},
+	"docs": &bintree{nil, map[string]*bintree{
+		"contract.html.tpl": &bintree{docsContractHtmlTpl, map[string]*bintree{}},
+		"contract.md.tpl":   &bintree{docsContractMdTpl, map[string]*bintree{}},
+		"index.html.tpl":    &bintree{docsIndexHtmlTpl, map[string]*bintree{}},
+		"index.md.tpl":      &bintree{docsIndexMdTpl, map[string]*bintree{}},
 	}},
 	"helpers.go": &bintree{helpersGo, map[string]*bintree{}},
 	"licenses": &bintree{nil, map[string]*bintree{
@@ -856,11 +1010,11 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"Foo.sol.tpl": &bintree{projectContractsFooSolTpl, map[string]*bintree{}},
 		}},
 		"generate.go.tpl": &bintree{projectGenerateGoTpl, map[string]*bintree{}},
+		"go.mod.tpl":      &bintree{projectGoModTpl, map[string]*bintree{}},
 		"main.go.tpl":     &bintree{projectMainGoTpl, map[string]*bintree{}},
 		"migrations": &bintree{nil, map[string]*bintree{
-			"1_Migrations.go": &bintree{projectMigrations1_migrationsGo, map[string]*bintree{}},
+			"1_Migrations.go.tpl": &bintree{projectMigrations1_migrationsGoTpl, map[string]*bintree{}},
 		}},
-		"cli.yaml.tpl": &bintree{projectCliYamlTpl, map[string]*bintree{}},
 		"stub": &bintree{nil, map[string]*bintree{
 			"README.md.tpl": &bintree{projectStubReadmeMdTpl, map[string]*bintree{}},
 			"main.go.tpl":   &bintree{projectStubMainGoTpl, map[string]*bintree{}},
@@ -869,12 +1023,14 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"tests": &bintree{nil, map[string]*bintree{
 			"Foo.go.tpl": &bintree{projectTestsFooGoTpl, map[string]*bintree{}},
 		}},
+		"wb.yaml.tpl": &bintree{projectWbYamlTpl, map[string]*bintree{}},
 	}},
 	"solc": &bintree{nil, map[string]*bintree{
 		"solc.json.tpl": &bintree{solcSolcJsonTpl, map[string]*bintree{}},
 	}},
 	"test": &bintree{nil, map[string]*bintree{
-		"test.go.tpl": &bintree{testTestGoTpl, map[string]*bintree{}},
+		"simulated.go.tpl": &bintree{testSimulatedGoTpl, map[string]*bintree{}},
+		"test.go.tpl":      &bintree{testTestGoTpl, map[string]*bintree{}},
 	}},
 }}
 
@@ -0,0 +1,5 @@
+# @version ^0.3.7
+
+@external
+def __init__():
+    pass
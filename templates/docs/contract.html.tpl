@@ -0,0 +1,34 @@
+{{define "params"}}{{range .}}<tr><td>{{if .Name}}<code>{{html .Name}}</code>{{end}}</td><td>{{if .Type}}<code>{{html .Type}}</code>{{end}}</td><td>{{html .Doc}}</td></tr>
+{{end}}</table>
+{{end}}{{define "member"}}<section class="member">
+<h3 id="{{html .Kind}}-{{html .Name}}">{{if .Name}}{{html .Name}}{{else}}{{html .Kind}}{{end}}</h3>
+<pre><code>{{html .Declaration}}</code></pre>
+{{if .Selector}}<p>{{if eq .Kind "event"}}Topic{{else}}Selector{{end}}: <code>{{.Selector}}</code></p>
+{{end}}{{if .Notice}}<p>{{html .Notice}}</p>
+{{end}}{{if .Details}}<p>{{html .Details}}</p>
+{{end}}{{if .Params}}<table>
+<tr><th>Parameter</th><th>Type</th><th>Description</th></tr>
+{{template "params" .Params}}{{end}}{{if .Returns}}<table>
+<tr><th>Returns</th><th>Type</th><th>Description</th></tr>
+{{template "params" .Returns}}{{end}}</section>
+{{end}}{{with .contract}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{html .Name}}</title>
+</head>
+<body>
+<p><a href="index.html">Contracts</a></p>
+<h1>{{html .Name}}</h1>
+{{if .Title}}<p><strong>{{html .Title}}</strong></p>
+{{end}}{{if .Notice}}<p>{{html .Notice}}</p>
+{{end}}{{if .Details}}<p>{{html .Details}}</p>
+{{end}}{{if .Author}}<p>Author: {{html .Author}}</p>
+{{end}}{{if .Constructor}}<h2>Constructor</h2>
+{{template "member" .Constructor}}{{end}}{{if .Functions}}<h2>Functions</h2>
+{{range .Functions}}{{template "member" .}}{{end}}{{end}}{{if .Events}}<h2>Events</h2>
+{{range .Events}}{{template "member" .}}{{end}}{{end}}{{if .Errors}}<h2>Errors</h2>
+{{range .Errors}}{{template "member" .}}{{end}}{{end}}{{if .Modifiers}}<h2>Modifiers</h2>
+{{range .Modifiers}}{{template "member" .}}{{end}}{{end}}</body>
+</html>
+{{end}}
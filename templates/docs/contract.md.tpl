@@ -0,0 +1,38 @@
+{{define "params"}}| --- | --- | --- |
+{{range .}}| {{if .Name}}`{{.Name}}`{{end}} | {{if .Type}}`{{.Type}}`{{end}} | {{.Doc}} |
+{{end}}{{end}}{{define "member"}}
+### {{if .Name}}{{.Name}}{{else}}{{.Kind}}{{end}}
+
+```solidity
+{{.Declaration}}
+```
+{{if .Selector}}
+{{if eq .Kind "event"}}Topic{{else}}Selector{{end}}: `{{.Selector}}`
+{{end}}{{if .Notice}}
+{{.Notice}}
+{{end}}{{if .Details}}
+{{.Details}}
+{{end}}{{if .Params}}
+| Parameter | Type | Description |
+{{template "params" .Params}}{{end}}{{if .Returns}}
+| Returns | Type | Description |
+{{template "params" .Returns}}{{end}}{{end}}{{with .contract}}# {{.Name}}
+{{if .Title}}
+**{{.Title}}**
+{{end}}{{if .Notice}}
+{{.Notice}}
+{{end}}{{if .Details}}
+{{.Details}}
+{{end}}{{if .Author}}
+Author: {{.Author}}
+{{end}}{{if .Constructor}}
+## Constructor
+{{template "member" .Constructor}}{{end}}{{if .Functions}}
+## Functions
+{{range .Functions}}{{template "member" .}}{{end}}{{end}}{{if .Events}}
+## Events
+{{range .Events}}{{template "member" .}}{{end}}{{end}}{{if .Errors}}
+## Errors
+{{range .Errors}}{{template "member" .}}{{end}}{{end}}{{if .Modifiers}}
+## Modifiers
+{{range .Modifiers}}{{template "member" .}}{{end}}{{end}}{{end}}
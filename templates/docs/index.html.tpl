@@ -0,0 +1,13 @@
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Contracts</title>
+</head>
+<body>
+<h1>Contracts</h1>
+<ul>
+{{range .contracts}}<li><a href="{{html .Name}}{{$.ext}}">{{html .Name}}</a>{{if .Title}}: {{html .Title}}{{else if .Notice}}: {{html .Notice}}{{end}}</li>
+{{end}}</ul>
+</body>
+</html>
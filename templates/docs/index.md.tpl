@@ -0,0 +1,4 @@
+# Contracts
+
+{{range .contracts}}- [{{.Name}}]({{.Name}}{{$.ext}}){{if .Title}}: {{.Title}}{{else if .Notice}}: {{.Notice}}{{end}}
+{{end}}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/polyswarm/perigord/contract"
+	"github.com/polyswarm/perigord/migration"
+	"github.com/polyswarm/perigord/network"
+	"github.com/zscole/cli/deployer"
+	"github.com/zscole/cli/deployments"
+
+	"{{.bindings}}"
+)
+
+type {{.contract}}Deployer struct{}
+
+func (d *{{.contract}}Deployer) Deploy(ctx context.Context, network *network.Network) (common.Address, *types.Transaction, interface{}, error) {
+	account, err := deployer.Unlock(network)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	// The binding's bytecode is unlinked, so contracts that use libraries
+	// are deployed as linked for this network
+	linked, err := deployments.LinkedBytecode("{{.contract}}")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if linked != "" {
+		{{.bindings_package}}.{{.type}}Bin = linked
+	}
+
+	auth := network.NewTransactor(account)
+	address, transaction, contract, err := {{.bindings_package}}.Deploy{{.type}}(auth, network.Client())
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	receipt, err := bind.WaitMined(ctx, network.Client(), transaction)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	err = deployments.RecordContract("{{.contract}}", deployments.Contract{
+		Address:      address.Hex(),
+		Transaction:  transaction.Hash().Hex(),
+		Block:        receipt.BlockNumber.Uint64(),
+		BytecodeHash: deployments.BytecodeHash({{.bindings_package}}.{{.type}}Bin),
+		Migration:    {{.number}},
+	})
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	session := &{{.bindings_package}}.{{.type}}Session{
+		Contract: contract,
+		CallOpts: bind.CallOpts{
+			Pending: true,
+		},
+		TransactOpts: *auth,
+	}
+
+	return address, transaction, session, nil
+}
+
+func (d *{{.contract}}Deployer) Bind(ctx context.Context, network *network.Network, address common.Address) (interface{}, error) {
+	account, err := deployer.Unlock(network)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := network.NewTransactor(account)
+	contract, err := {{.bindings_package}}.New{{.type}}(address, network.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	session := &{{.bindings_package}}.{{.type}}Session{
+		Contract: contract,
+		CallOpts: bind.CallOpts{
+			Pending: true,
+		},
+		TransactOpts: *auth,
+	}
+
+	return session, nil
+}
+
+func init() {
+	contract.AddContract("{{.contract}}", &{{.contract}}Deployer{})
+
+	migration.AddMigration(&migration.Migration{
+		Number: {{.number}},
+		F: func(ctx context.Context, network *network.Network) error {
+			if err := contract.Deploy(ctx, "{{.contract}}", network); err != nil {
+				return err
+			}
+
+			return deployments.RecordMigration({{.number}})
+		},
+	})
+}
@@ -0,0 +1,11 @@
+module {{.project}}
+
+go {{.go}}
+{{- if .requires}}
+
+require (
+{{- range .requires}}
+	{{.Path}} {{.Version}}
+{{- end}}
+)
+{{- end}}
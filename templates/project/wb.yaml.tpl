@@ -0,0 +1,70 @@
+project: {{.project}}
+license: {{.license.Name}}
+
+# Vyper contracts only use optimizer.enabled and evm_version
+compiler:
+    optimizer:
+        enabled: true
+        runs: 200
+    # evm_version: byzantium
+    # remappings:
+    #     - openzeppelin/=lib/openzeppelin/contracts/
+    # include_paths:
+    #     - node_modules
+    # warnings_as_errors: false
+    # suppressed_codes:
+    #     - "2072"
+    # overrides:
+    #     - contracts: [Foo]
+    #       optimizer:
+    #           runs: 1000
+
+# artifacts:
+#     format: split # split, combined or both
+
+# size:
+#     warn_percent: 90
+#     over_limit: warn # warn or fail
+
+# proxies:
+#     - name: Diamond
+#       contracts: [Diamond, OwnershipFacet]
+
+# bindings:
+#     package: bindings
+#     output: bindings
+#     types:
+#         Foo: FooContract
+
+# wb node serves a local chain for the dev network
+# node:
+#     network: dev
+#     http: 127.0.0.1:8545 # or none
+#     ipc: build/node.ipc # or none
+#     chain_id: 1337
+#     block_time: 5s # 0 mines each transaction at once
+#     accounts: 10
+#     balance: 10000 # ether per account
+#     migrate: false
+#     reset: false # clear the deployments recorded for the network
+
+# Network `wb test` runs tests on, unless given --network
+test_network: simulated
+
+networks:
+    # An in-process chain that each test suite starts afresh
+    simulated:
+        type: simulated
+        # accounts: 10
+        # balance: 10000 # ether per account
+    # The chain `wb node` serves, deploying from the accounts it saves
+    dev:
+        url: http://127.0.0.1:8545
+        keystore: build/node/keystore
+        # chain_id: 1337 # checked before anything is deployed
+        # gas_price: 20 # gwei
+        # gas_limit: 6000000
+        # deployer: 0 # address or keystore index, see wb accounts list
+        # password_file: .password # or set WB_PASSWORD
+        # libraries:
+        #     SafeMath: "0x0000000000000000000000000000000000000000"
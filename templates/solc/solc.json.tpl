@@ -1,31 +1,12 @@
 {
-  "language": "Solidity",
+  "language": "{{.language}}",
   "sources": {
-    {{range $index, $match := .}}
+    {{range $index, $match := .sources}}
     {{if $index}},{{end}}
     "{{$match.Filename}}": {
       "content": {{$match.Content}}
     }
     {{end}}
   },
-  "settings": {
-    "optimizer": {
-        "enabled": true,
-        "runs": 200
-    },
-    "outputSelection": {
-      "*": {
-        "*": [
-          "abi",
-          "ast",
-          "evm.bytecode.object",
-          "evm.bytecode.sourceMap",
-          "evm.bytecode.linkReferences",
-          "evm.deployedBytecode.object",
-          "evm.deployedBytecode.sourceMap",
-          "evm.deployedBytecode.linkReferences"
-        ]
-      }
-    }
-  }
+  "settings": {{.settings}}
 }
\ No newline at end of file
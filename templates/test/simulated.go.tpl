@@ -0,0 +1,51 @@
+package tests
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/zscole/cli/simulated"
+)
+
+type {{.test}}Suite struct {
+	chain    *simulated.Chain
+	snapshot int
+}
+
+var _ = Suite(&{{.test}}Suite{})
+
+func (s *{{.test}}Suite) SetUpSuite(c *C) {
+	chain, err := simulated.FromEnv()
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	s.chain = chain
+
+	// Deploy the contracts the suite shares here, with the bindings in
+	// {{.bindings}}, e.g.
+	//
+	//	auth, _ := s.chain.Transactor(0)
+	//	address, _, contract, err := {{.bindings_package}}.DeployFoo(auth, s.chain)
+}
+
+func (s *{{.test}}Suite) TearDownSuite(c *C) {
+	s.chain.Close()
+}
+
+// Every test starts from the chain as SetUpSuite left it
+func (s *{{.test}}Suite) SetUpTest(c *C) {
+	snapshot, err := s.chain.Snapshot()
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	s.snapshot = snapshot
+}
+
+func (s *{{.test}}Suite) TearDownTest(c *C) {
+	if err := s.chain.Revert(s.snapshot); err != nil {
+		c.Fatal(err)
+	}
+}
+
+// USER TESTS GO HERE